@@ -0,0 +1,196 @@
+// Copyright 2021 Siôn le Roux.  All rights reserved.
+// Use of this source code is subject to an MIT-style
+// licence which can be found in the LICENSE file.
+
+package cr1ckt
+
+import (
+	"image"
+	"image/color"
+	"log"
+	"math/rand"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+// BlacknessSize is the size of each black square in pixels
+const BlacknessSize int = 16
+
+// Spaces the blackness can be in
+const (
+	// BlacknessScreen is blackness that stays in place on the screen while
+	// the level scrolls under it
+	BlacknessScreen = "screen"
+	// BlacknessWorld is blackness that covers tiles in the level, like fog
+	// that stays where it is when the camera moves away
+	BlacknessWorld = "world"
+)
+
+// Blackness is a map to store where black squares should appear, either on
+// the camera's grid or on the level's grid depending on the blackness space
+type Blackness map[image.Point]bool
+
+// Has is a convenience function to check if a point on the camera is already
+// black, it can be used in-line because it only returns one value
+func (b Blackness) Has(v image.Point) bool {
+	_, ok := b[v]
+	return ok
+}
+
+// Copy returns a new Blackness with the same black squares, so that changing
+// one doesn't affect the other
+func (b Blackness) Copy() Blackness {
+	c := make(Blackness, len(b))
+	for k, v := range b {
+		c[k] = v
+	}
+	return c
+}
+
+// shiftSquares moves black squares, or anything else kept by square, by the
+// given offset in pixels, e.g. when the level they're in moves
+func shiftSquares[M ~map[image.Point]V, V any](squares M, offset image.Point) M {
+	shift := image.Pt(floorDiv(offset.X, BlacknessSize), floorDiv(offset.Y, BlacknessSize))
+	shifted := make(M, len(squares))
+	for b, v := range squares {
+		shifted[b.Add(shift)] = v
+	}
+	return shifted
+}
+
+// BlacknessCurve decides how many black squares are added to the screen as the
+// cricket jumps around and as time passes
+type BlacknessCurve interface {
+	// Jumped returns how many squares to add for the given jump, counting
+	// from 1
+	Jumped(jumps int) int
+	// Ticked returns how many squares to add for the given tick, counting
+	// from 1
+	Ticked(ticks int) int
+}
+
+// NewBlacknessCurve returns the blackness curve for the mode with the given
+// name, using the factor to set how fast the blackness grows, unknown names
+// fall back to the classic mode
+func NewBlacknessCurve(name string, factor int) BlacknessCurve {
+	if factor < 1 {
+		factor = 1
+	}
+	switch name {
+	case "classic", "":
+		return ClassicBlackness(factor)
+	case "exponential":
+		return ExponentialBlackness(factor)
+	case "linear":
+		return LinearBlackness(factor)
+	case "time":
+		return TimeBlackness(factor)
+	case "off":
+		return NoBlackness{}
+	}
+	log.Printf("Unknown blackness mode %q, using classic\n", name)
+	return ClassicBlackness(factor)
+}
+
+// ClassicBlackness is how blackness worked in the original jam version, it's
+// meant to double every factor jumps but uses XOR instead of a power so it
+// adds 2, 3, 0, 1, 6, 7, 4, 5... squares per jump as the factor is reached
+type ClassicBlackness int
+
+// Jumped returns 2 XOR (jumps / factor)
+func (f ClassicBlackness) Jumped(jumps int) int {
+	return 2 ^ (jumps / int(f))
+}
+
+// Ticked never adds blackness
+func (f ClassicBlackness) Ticked(ticks int) int { return 0 }
+
+// ExponentialBlackness doubles the squares added per jump every factor jumps
+type ExponentialBlackness int
+
+// maxBlacknessShift stops exponential blackness overflowing, by then the whole
+// screen is black anyway
+const maxBlacknessShift = 16
+
+// Jumped returns 2 to the power of (jumps / factor)
+func (f ExponentialBlackness) Jumped(jumps int) int {
+	shift := jumps / int(f)
+	if shift > maxBlacknessShift {
+		shift = maxBlacknessShift
+	}
+	return 1 << shift
+}
+
+// Ticked never adds blackness
+func (f ExponentialBlackness) Ticked(ticks int) int { return 0 }
+
+// LinearBlackness adds one more square per jump every factor jumps
+type LinearBlackness int
+
+// Jumped returns 1 + jumps / factor
+func (f LinearBlackness) Jumped(jumps int) int {
+	return 1 + jumps/int(f)
+}
+
+// Ticked never adds blackness
+func (f LinearBlackness) Ticked(ticks int) int { return 0 }
+
+// TimeBlackness adds a square every factor seconds whether you jump or not
+type TimeBlackness int
+
+// Jumped never adds blackness
+func (f TimeBlackness) Jumped(jumps int) int { return 0 }
+
+// Ticked returns 1 every factor seconds
+func (f TimeBlackness) Ticked(ticks int) int {
+	if ticks%(int(f)*60) == 0 {
+		return 1
+	}
+	return 0
+}
+
+// NoBlackness turns the blackness off
+type NoBlackness struct{}
+
+// Jumped never adds blackness
+func (NoBlackness) Jumped(jumps int) int { return 0 }
+
+// Ticked never adds blackness
+func (NoBlackness) Ticked(ticks int) int { return 0 }
+
+// addBlackness adds n black squares in random places on the screen, in world
+// space that means on whichever tiles are currently in view
+func (g *Game) addBlackness(n int) {
+	var offset image.Point
+	if g.rules.BlacknessSpace == BlacknessWorld {
+		offset = image.Pt(int(g.cam.X)-g.Width/2, int(g.cam.Y)-g.Height/2)
+	}
+	for i := 0; i < n; i++ {
+		g.blackness[image.Pt(
+			(offset.X+rand.Intn(g.Width))/BlacknessSize,
+			(offset.Y+rand.Intn(g.Height))/BlacknessSize,
+		)] = true
+	}
+}
+
+// drawBlackness draws the black squares, in screen space they go straight on
+// the screen and in world space they go on the camera surface so they scroll
+// with the level
+func (g *Game) drawBlackness(surface *ebiten.Image) {
+	for b := range g.blackness {
+		alpha := g.revealedAlpha(b)
+		if alpha == 0 {
+			continue
+		}
+		x, y := float64(b.X*BlacknessSize), float64(b.Y*BlacknessSize)
+		if g.rules.BlacknessSpace == BlacknessWorld {
+			x, y = g.cam.GetTranslation(x, y).GeoM.Apply(0, 0)
+		}
+		ebitenutil.DrawRect(surface,
+			x, y,
+			float64(BlacknessSize), float64(BlacknessSize),
+			color.RGBA{0, 0, 0, uint8(alpha * 0xff)},
+		)
+	}
+}
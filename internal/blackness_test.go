@@ -0,0 +1,58 @@
+package cr1ckt
+
+import (
+	"image"
+	"testing"
+)
+
+func TestBlacknessModes(t *testing.T) {
+	const jumps, ticks = 20, 600 // 10 seconds
+	cases := []struct {
+		curve     BlacknessCurve
+		wantJumps int
+		wantTicks int
+		comment   string
+	}{
+		{NewBlacknessCurve("classic", 10), 9*2 + 10*3 + 1*0, 0, "classic XOR"},
+		{NewBlacknessCurve("", 10), 9*2 + 10*3 + 1*0, 0, "default is classic"},
+		{NewBlacknessCurve("nonsense", 10), 9*2 + 10*3 + 1*0, 0, "unknown is classic"},
+		{NewBlacknessCurve("exponential", 10), 9*1 + 10*2 + 1*4, 0, "exponential"},
+		{NewBlacknessCurve("exponential", 1), 1<<16 - 2 + 5*1<<16, 0, "exponential capped"},
+		{NewBlacknessCurve("linear", 10), 9*1 + 10*2 + 1*3, 0, "linear"},
+		{NewBlacknessCurve("linear", 0), 20*21/2 + 20, 0, "linear with factor clamped to 1"},
+		{NewBlacknessCurve("time", 2), 0, 5, "time every 2 seconds"},
+		{NewBlacknessCurve("off", 10), 0, 0, "off"},
+	}
+	for _, c := range cases {
+		var gotJumps, gotTicks int
+		for i := 1; i <= jumps; i++ {
+			gotJumps += c.curve.Jumped(i)
+		}
+		for i := 1; i <= ticks; i++ {
+			gotTicks += c.curve.Ticked(i)
+		}
+		if gotJumps != c.wantJumps {
+			t.Errorf("%s adds %d squares over %d jumps, want %d", c.comment, gotJumps, jumps, c.wantJumps)
+		}
+		if gotTicks != c.wantTicks {
+			t.Errorf("%s adds %d squares over %d ticks, want %d", c.comment, gotTicks, ticks, c.wantTicks)
+		}
+	}
+}
+
+func TestShiftSquares(t *testing.T) {
+	b := Blackness{image.Pt(0, 0): true, image.Pt(3, -1): true}
+	got := shiftSquares(b, image.Pt(-32, 48))
+	want := Blackness{image.Pt(-2, 3): true, image.Pt(1, 2): true}
+	if len(got) != len(want) {
+		t.Fatalf("Shifted blackness is %v, want %v", got, want)
+	}
+	for p := range want {
+		if !got.Has(p) {
+			t.Errorf("Shifted blackness is %v, want %v", got, want)
+		}
+	}
+	if !b.Has(image.Pt(0, 0)) {
+		t.Error("Shifting changed the original blackness")
+	}
+}
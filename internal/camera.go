@@ -0,0 +1,227 @@
+// Copyright 2021 Siôn le Roux.  All rights reserved.
+// Use of this source code is subject to an MIT-style
+// licence which can be found in the LICENSE file.
+
+package cr1ckt
+
+import (
+	"image"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	camera "github.com/melonfunction/ebiten-camera"
+)
+
+// cameraZoomStep is how much further the camera surface is made big enough to
+// zoom out when it has to grow, so easing the zoom out doesn't make a new
+// surface every tick
+const cameraZoomStep = 0.1
+
+// cameraMaxSurface is the biggest the camera surface can be made, zooming out
+// further than that shows the edges of the surface
+const cameraMaxSurface = 16384
+
+// CameraController moves the camera smoothly after the cricket, keeping it
+// inside the level
+type CameraController struct {
+	X, Y  float64 // Where the camera is looking, in level pixels
+	Zoom  float64
+	lookX float64 // Current look-ahead offset, eased like the position
+	snap  bool
+	Shake Shake
+}
+
+// NewCameraController returns a new CameraController that snaps to its first
+// target
+func NewCameraController() *CameraController {
+	return &CameraController{Zoom: 1, snap: true}
+}
+
+// Snap makes the camera jump straight to its next target instead of easing
+// there, e.g. when the level starts
+func (c *CameraController) Snap() {
+	c.snap = true
+}
+
+// Shift moves the camera along with the level when the level's pixels move
+// under it, e.g. when crossing into a neighbouring level, so it carries on
+// easing from where it was
+func (c *CameraController) Shift(offset image.Point) {
+	c.snap = false
+	c.X += float64(offset.X)
+	c.Y += float64(offset.Y)
+}
+
+// Follow moves the camera towards the target, which is facing the given
+// direction (1 is left, -1 is right like Cricket.Direction), and zooms out by
+// the prime fraction from 0 to 1, all while keeping the view inside the bounds;
+// if the target is in a camera zone the camera eases into that zone instead
+func (c *CameraController) Follow(target image.Point, direction int, prime float64, view image.Point, bounds image.Rectangle, zone *CameraZone) {
+	zoom := 1 / (1 + CameraPrimeZoom*math.Min(math.Max(prime, 0), 1))
+	lookX := -float64(direction * CameraLookAhead)
+	tx, ty := float64(target.X), float64(target.Y)
+	viewW, viewH := float64(view.X)/c.Zoom, float64(view.Y)/c.Zoom
+
+	if c.snap {
+		c.X, c.Y, c.lookX, c.Zoom = tx+lookX, ty, lookX, zoom
+	} else {
+		c.lookX += (lookX - c.lookX) * CameraLerp
+		c.Zoom += (zoom - c.Zoom) * CameraLerp
+	}
+
+	// Only follow the part of the movement outside the deadzone
+	want := func(cam, target float64, deadzone int) float64 {
+		d := float64(deadzone)
+		if target > cam+d {
+			return target - d
+		}
+		if target < cam-d {
+			return target + d
+		}
+		return cam
+	}
+	x := want(c.X-c.lookX, tx, CameraDeadzoneX) + c.lookX
+	y := want(c.Y, ty, CameraDeadzoneY)
+	if zone != nil {
+		if zone.Lock {
+			x = float64(zone.Rect.Min.X+zone.Rect.Max.X) / 2
+			y = float64(zone.Rect.Min.Y+zone.Rect.Max.Y) / 2
+		}
+		x, y = ClampCamera(x, y, viewW, viewH, zone.Rect)
+	}
+
+	if c.snap {
+		c.snap = false
+		c.X, c.Y = x, y
+	} else {
+		c.X += (x - c.X) * CameraLerp
+		c.Y += (y - c.Y) * CameraLerp
+	}
+
+	c.X, c.Y = ClampCamera(c.X, c.Y, viewW, viewH, bounds)
+}
+
+// LookAt puts the camera straight at a position, still keeping the view inside
+// the bounds, for when something other than the cricket is in charge of it
+func (c *CameraController) LookAt(x, y float64, view image.Point, bounds image.Rectangle) {
+	c.snap = false
+	c.Zoom = 1
+	c.X, c.Y = ClampCamera(x, y, float64(view.X), float64(view.Y), bounds)
+}
+
+// Overview zooms the camera out to fit the whole of the bounds in the view, the
+// zoom eases so letting go of the overview zooms smoothly back in
+func (c *CameraController) Overview(view image.Point, bounds image.Rectangle) {
+	zoom := math.Min(
+		float64(view.X)/float64(bounds.Dx()),
+		float64(view.Y)/float64(bounds.Dy()),
+	)
+	c.Zoom += (math.Min(zoom, 1) - c.Zoom) * CameraLerp
+	c.X += (float64(bounds.Min.X+bounds.Max.X)/2 - c.X) * CameraLerp
+	c.Y += (float64(bounds.Min.Y+bounds.Max.Y)/2 - c.Y) * CameraLerp
+	c.X, c.Y = ClampCamera(c.X, c.Y,
+		float64(view.X)/c.Zoom, float64(view.Y)/c.Zoom,
+		bounds,
+	)
+}
+
+// Apply sets the ebiten camera to look where the controller is looking, plus
+// any screen shake. The zoom is only applied when the surface is drawn to the
+// screen by Blit, the surface just has to be big enough to hold what's in view,
+// so it's only made bigger when zooming out further than before.
+func (c *CameraController) Apply(cam *camera.Camera) {
+	cam.Scale = c.Zoom
+	w, h := cam.Surface.Size()
+	if float64(w) < float64(cam.Width)/c.Zoom || float64(h) < float64(cam.Height)/c.Zoom {
+		zoom := math.Floor(c.Zoom/cameraZoomStep) * cameraZoomStep
+		if zoom <= 0 {
+			zoom = c.Zoom
+		}
+		cam.Surface.Dispose()
+		cam.Surface = ebiten.NewImage(
+			cameraSurfaceSize(cam.Width, zoom), cameraSurfaceSize(cam.Height, zoom),
+		)
+	}
+	dx, dy := c.Shake.Offset()
+	cam.SetPosition(snap(c.X+dx), snap(c.Y+dy))
+}
+
+// cameraSurfaceSize returns how big the camera surface has to be along one
+// side to show the given size of screen at the given zoom, it's kept even so
+// the middle of the surface is on a whole pixel
+func cameraSurfaceSize(screen int, zoom float64) int {
+	size := int(math.Ceil(float64(screen) / zoom))
+	size += size % 2
+	if size > cameraMaxSurface {
+		return cameraMaxSurface
+	}
+	return size
+}
+
+// Blit draws the camera surface to the screen, zoomed around the middle of the
+// screen, the surface can be bigger than what's in view so the edges of it may
+// be off the screen
+func (c *CameraController) Blit(cam *camera.Camera, screen *ebiten.Image) {
+	w, h := cam.Surface.Size()
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(-float64(w)/2, -float64(h)/2)
+	op.GeoM.Scale(cam.Scale, cam.Scale)
+	op.GeoM.Translate(float64(cam.Width)/2, float64(cam.Height)/2)
+	screen.DrawImage(cam.Surface, op)
+}
+
+func init() {
+	RegisterEntity("CameraZone", func(g *Game, e *MapEntity) Entity {
+		return NewCameraZone(e)
+	})
+}
+
+// CameraZone is an area of the level from a "CameraZone" entity in LDtk, while
+// the cricket is inside it the camera stays inside it too, and if its Lock
+// field is set the camera stays still in the middle of it
+type CameraZone struct {
+	Rect image.Rectangle
+	Lock bool
+}
+
+// NewCameraZone returns a new CameraZone covering the given LDtk entity
+func NewCameraZone(e *MapEntity) *CameraZone {
+	z := &CameraZone{Rect: entityRect(e)}
+	if p := e.PropertyByIdentifier("Lock"); p != nil && !p.IsNull() {
+		z.Lock = p.AsBool()
+	}
+	return z
+}
+
+// Hitbox returns the area of the camera zone
+func (z *CameraZone) Hitbox() image.Rectangle {
+	return z.Rect
+}
+
+// cameraZone returns the camera zone the cricket is in, or nil if it isn't in
+// one, if zones overlap the first one wins
+func (g *Game) cameraZone() *CameraZone {
+	hitbox := g.Cricket.Hitbox()
+	center := hitbox.Min.Add(hitbox.Size().Div(2))
+	for _, z := range g.cameraZones {
+		if center.In(z.Rect) {
+			return z
+		}
+	}
+	return nil
+}
+
+// ClampCamera returns the closest camera centre to x, y that keeps a view of
+// the given size inside the bounds, if the view is bigger than the bounds on
+// an axis it's centred on that axis instead
+func ClampCamera(x, y, viewW, viewH float64, bounds image.Rectangle) (float64, float64) {
+	clamp := func(v, view float64, min, max int) float64 {
+		lo, hi := float64(min)+view/2, float64(max)-view/2
+		if lo >= hi {
+			return float64(min+max) / 2
+		}
+		return math.Min(math.Max(v, lo), hi)
+	}
+	return clamp(x, viewW, bounds.Min.X, bounds.Max.X),
+		clamp(y, viewH, bounds.Min.Y, bounds.Max.Y)
+}
@@ -0,0 +1,52 @@
+package cr1ckt
+
+import (
+	"image"
+	"testing"
+)
+
+func TestClampCamera(t *testing.T) {
+	const viewW, viewH = 640, 480
+	big := image.Rect(0, 0, 1440, 1440)
+	small := image.Rect(0, 0, 320, 240)
+	wide := image.Rect(0, 0, 1440, 240)
+	cases := []struct {
+		x, y         float64
+		bounds       image.Rectangle
+		wantX, wantY float64
+		comment      string
+	}{
+		{720, 720, big, 720, 720, "middle of big level"},
+		{0, 0, big, 320, 240, "top left of big level"},
+		{1440, 1440, big, 1120, 1200, "bottom right of big level"},
+		{0, 0, small, 160, 120, "small level is centred"},
+		{300, 200, small, 160, 120, "small level is always centred"},
+		{0, 0, wide, 320, 120, "short level is centred only vertically"},
+		{100, 100, image.Rect(1000, 1000, 2440, 2440), 1320, 1240, "level not at origin"},
+	}
+	for _, c := range cases {
+		x, y := ClampCamera(c.x, c.y, viewW, viewH, c.bounds)
+		if x != c.wantX || y != c.wantY {
+			t.Errorf("Camera at %s is (%v, %v), want (%v, %v)", c.comment, x, y, c.wantX, c.wantY)
+		}
+	}
+}
+
+func TestCameraSurfaceSize(t *testing.T) {
+	cases := []struct {
+		screen int
+		zoom   float64
+		want   int
+	}{
+		{640, 1, 640},
+		{640, 0.9, 712},
+		{480, 0.3, 1600},
+		{641, 1, 642},
+		{640, 0.001, cameraMaxSurface},
+	}
+	for _, c := range cases {
+		if got := cameraSurfaceSize(c.screen, c.zoom); got != c.want {
+			t.Errorf("Surface for %d pixels at zoom %v is %d, want %d", c.screen, c.zoom, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2021 Siôn le Roux.  All rights reserved.
+// Use of this source code is subject to an MIT-style
+// licence which can be found in the LICENSE file.
+
+package cr1ckt
+
+import (
+	"image"
+	"log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func init() {
+	RegisterEntity("Checkpoint", func(g *Game, e *MapEntity) Entity {
+		return NewCheckpoint(e)
+	})
+}
+
+// Checkpoint is a place in the level from a "Checkpoint" entity in LDtk, when
+// the cricket touches it that's where it comes back after hitting a hazard
+type Checkpoint struct {
+	Rect    image.Rectangle
+	Reached bool
+}
+
+// NewCheckpoint returns a new Checkpoint covering the given LDtk entity
+func NewCheckpoint(e *MapEntity) *Checkpoint {
+	return &Checkpoint{Rect: entityRect(e)}
+}
+
+// Hitbox returns the area of the checkpoint the cricket has to touch
+func (c *Checkpoint) Hitbox() image.Rectangle {
+	return c.Rect
+}
+
+// Touch reaches the checkpoint the first time the cricket touches it, so the
+// cricket respawns there
+func (c *Checkpoint) Touch(g *Game) {
+	if c.Reached {
+		return
+	}
+	log.Println("Reached a checkpoint")
+	c.Reached = true
+	g.respawn = g.snapshot(c.Spawn(
+		g.Cricket.Width, g.Cricket.Image.Bounds().Dy(),
+	))
+}
+
+// Draw draws the checkpoint's flag to the camera surface, raised once it's
+// been reached
+func (c *Checkpoint) Draw(g *Game) {
+	frame := 0
+	if c.Reached {
+		frame = 1
+	}
+	pos := c.Spawn(16, 16)
+	g.cam.Surface.DrawImage(g.flagImg.SubImage(image.Rect(
+		frame*16, 0, (frame+1)*16, 16,
+	)).(*ebiten.Image), g.cam.GetTranslation(float64(pos.X), float64(pos.Y)))
+}
+
+// Spawn returns where a cricket of the given size should be placed to stand
+// on the bottom middle of the checkpoint
+func (c *Checkpoint) Spawn(width, height int) image.Point {
+	return image.Pt(
+		c.Rect.Min.X+c.Rect.Dx()/2-width/2,
+		c.Rect.Max.Y-height,
+	)
+}
+
+// RunState is a snapshot of the progress through a level, taken at a
+// checkpoint so it can be restored when the cricket respawns there
+type RunState struct {
+	Position  image.Point
+	Jumps     int
+	Ticks     int
+	Chirps    int
+	Blackness Blackness
+	Seeds     []bool
+	Doors     []Door
+	Switches  []Switch
+	Keys      []Key
+}
+
+// snapshot takes a RunState of the current level to respawn at the given
+// position later
+func (g *Game) snapshot(pos image.Point) *RunState {
+	state := &RunState{
+		Position:  pos,
+		Jumps:     debugNumberOfJumps,
+		Ticks:     g.ticks,
+		Chirps:    g.chirpsLeft,
+		Blackness: g.blackness.Copy(),
+	}
+	for _, s := range g.seeds {
+		state.Seeds = append(state.Seeds, s.Collected)
+	}
+	for _, d := range g.doors {
+		state.Doors = append(state.Doors, *d)
+	}
+	for _, s := range EntitiesOf[*Switch](g.entities) {
+		state.Switches = append(state.Switches, *s)
+	}
+	for _, k := range g.keys {
+		state.Keys = append(state.Keys, *k)
+	}
+	return state
+}
+
+// Respawn puts the cricket back at the last checkpoint it reached, with the
+// level how it was at that time, or restarts the level if there isn't one or
+// the level is set up with FullRestart
+func (g *Game) Respawn() {
+	if g.respawn == nil || g.LevelBool("FullRestart", false) {
+		g.Reset(g.Level)
+		return
+	}
+	log.Println("Respawning at checkpoint", g.respawn.Position)
+	g.Cricket = NewCricket([]int{g.respawn.Position.X, g.respawn.Position.Y})
+	g.blackness = g.respawn.Blackness.Copy()
+	g.ticks = g.respawn.Ticks
+	g.chirpsLeft = g.respawn.Chirps
+	g.chirp = nil
+	for i, s := range g.seeds {
+		s.Collected = g.respawn.Seeds[i]
+	}
+	for i, d := range g.doors {
+		*d = g.respawn.Doors[i]
+	}
+	for i, s := range EntitiesOf[*Switch](g.entities) {
+		*s = g.respawn.Switches[i]
+	}
+	for i, k := range g.keys {
+		*k = g.respawn.Keys[i]
+	}
+	debugNumberOfJumps = g.respawn.Jumps
+	g.view.Snap()
+}
@@ -0,0 +1,84 @@
+package cr1ckt
+
+import (
+	"image"
+	"strings"
+	"testing"
+)
+
+func TestRespawnAtCheckpoint(t *testing.T) {
+	g := newTestGame(t, testLevel)
+	g.ticks, g.chirpsLeft, debugNumberOfJumps = 20, 2, 3
+	g.blackness[image.Pt(1, 1)] = true
+	EntitiesOf[*Checkpoint](g.entities)[0].Touch(g)
+	spawn := g.respawn.Position
+
+	// Everything that happens after the checkpoint is undone
+	g.ticks, g.chirpsLeft, debugNumberOfJumps = 90, 0, 8
+	g.blackness[image.Pt(2, 2)] = true
+	g.seeds[0].Collected = true
+	g.keys[0].Touch(g)
+	EntitiesOf[*Switch](g.entities)[0].Press()
+	door := g.doors[0]
+	if !g.unlockDoor(door) {
+		t.Fatal("Door should open with the switch pressed and the key")
+	}
+	g.die(DeathWater)
+
+	if g.Cricket.Position != spawn {
+		t.Errorf("Respawned at %v, want the checkpoint at %v", g.Cricket.Position, spawn)
+	}
+	if g.ticks != 20 || g.chirpsLeft != 2 || debugNumberOfJumps != 3 {
+		t.Errorf("Respawned with %d ticks, %d chirps and %d jumps, want 20, 2 and 3",
+			g.ticks, g.chirpsLeft, debugNumberOfJumps)
+	}
+	if len(g.blackness) != 1 || !g.blackness[image.Pt(1, 1)] {
+		t.Errorf("Respawned with blackness %v, want only 1, 1", g.blackness)
+	}
+	if g.seeds[0].Collected {
+		t.Error("Seed collected after the checkpoint should be back")
+	}
+	if k := g.keys[0]; k.Carried || k.Used {
+		t.Errorf("Key picked up after the checkpoint is %+v, want it back where it was", k)
+	}
+	if EntitiesOf[*Switch](g.entities)[0].Pressed {
+		t.Error("Switch pressed after the checkpoint should be up again")
+	}
+	if door.Open() || door.Unlocked || door.Pressed {
+		t.Errorf("Door opened after the checkpoint is %+v, want it closed and locked", door)
+	}
+	if g.respawn == nil || g.respawn.Position != spawn {
+		t.Error("Checkpoint should still be there to respawn at again")
+	}
+}
+
+func TestRespawnFullRestart(t *testing.T) {
+	level := strings.Replace(testLevel, "<tileset", `<properties>
+  <property name="FullRestart" type="bool" value="true"/>
+ </properties>
+ <tileset`, 1)
+	g := newTestGame(t, level)
+	start := g.Cricket.Position
+	EntitiesOf[*Checkpoint](g.entities)[0].Touch(g)
+	g.ticks, g.chirpsLeft, debugNumberOfJumps = 90, 0, 8
+	g.seeds[0].Collected = true
+	g.keys[0].Touch(g)
+	g.die(DeathWater)
+
+	if g.Cricket.Position != start {
+		t.Errorf("Restarted at %v, want the start of the level at %v", g.Cricket.Position, start)
+	}
+	if g.respawn != nil {
+		t.Error("Restarting the level should forget the checkpoint")
+	}
+	if g.ticks != 0 || g.chirpsLeft != g.rules.ChirpUses || debugNumberOfJumps != 0 {
+		t.Errorf("Restarted with %d ticks, %d chirps and %d jumps, want 0, %d and 0",
+			g.ticks, g.chirpsLeft, debugNumberOfJumps, g.rules.ChirpUses)
+	}
+	if g.seeds[0].Collected || g.keys[0].Carried {
+		t.Error("Restarting the level should put the seed and key back")
+	}
+	if EntitiesOf[*Checkpoint](g.entities)[0].Reached {
+		t.Error("Restarting the level should lower the checkpoint flag")
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2021 Siôn le Roux.  All rights reserved.
+// Use of this source code is subject to an MIT-style
+// licence which can be found in the LICENSE file.
+
+package cr1ckt
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	chirpRadius   = 160 // How far the ring spreads in pixels
+	chirpSpeed    = 4   // How fast the ring spreads in pixels per tick
+	chirpCooldown = 120 // Ticks before the cricket can chirp again
+	chirpReveal   = 300 // Ticks a black square stays cleared
+	chirpFade     = 60  // Ticks a cleared square takes to fade back in
+)
+
+// Chirp is a ring of sound spreading out from the cricket that clears the
+// blackness it passes over for a while
+type Chirp struct {
+	Center image.Point // Where the ring started, in the blackness's space
+	Radius float64
+}
+
+// chirpPressed returns whether the player just pressed the chirp button, which
+// is C, Up or W on the keyboard or tapping the top of the touch screen
+func (g *Game) chirpPressed() bool {
+	if inpututil.IsKeyJustPressed(ebiten.KeyC) ||
+		inpututil.IsKeyJustPressed(ebiten.KeyUp) ||
+		inpututil.IsKeyJustPressed(ebiten.KeyW) {
+		return true
+	}
+	for _, id := range inpututil.AppendJustPressedTouchIDs(nil) {
+		if _, y := ebiten.TouchPosition(id); y < g.Height/5 {
+			return true
+		}
+	}
+	return false
+}
+
+// chirpsOn returns whether the cricket can chirp in the current level, there's
+// no point when blackness is off because there's nothing to clear
+func (g *Game) chirpsOn() bool {
+	return g.rules.BlacknessMode != "off"
+}
+
+// updateChirp starts a chirp when the button is pressed, if the cricket has
+// chirps left, and spreads the ring of any chirp in progress
+func (g *Game) updateChirp() {
+	if g.chirpWait > 0 {
+		g.chirpWait--
+	}
+
+	// Cleared squares come back after a while
+	for b, t := range g.revealed {
+		if t <= 1 {
+			delete(g.revealed, b)
+		} else {
+			g.revealed[b] = t - 1
+		}
+	}
+
+	if g.chirp == nil && g.chirpWait == 0 && g.chirpsLeft > 0 && g.chirpsOn() && g.chirpPressed() {
+		g.chirpsLeft--
+		g.chirpWait = chirpCooldown
+		center := g.Cricket.Position.Add(image.Pt(
+			g.Cricket.Width/2, g.Cricket.Image.Bounds().Dy()/2,
+		))
+		if g.rules.BlacknessSpace != BlacknessWorld {
+			x, y := g.cam.GetScreenCoords(float64(center.X), float64(center.Y))
+			center = image.Pt(int(x), int(y))
+		}
+		g.chirp = &Chirp{Center: center}
+		g.chirpPlayer.Rewind()
+		g.chirpPlayer.Play()
+	}
+
+	if g.chirp == nil {
+		return
+	}
+	g.chirp.Radius += chirpSpeed
+	for b := range g.blackness {
+		cell := image.Pt(
+			b.X*BlacknessSize+BlacknessSize/2,
+			b.Y*BlacknessSize+BlacknessSize/2,
+		).Sub(g.chirp.Center)
+		if math.Hypot(float64(cell.X), float64(cell.Y)) <= g.chirp.Radius {
+			g.revealed[b] = chirpReveal
+		}
+	}
+	if g.chirp.Radius >= chirpRadius {
+		g.chirp = nil
+	}
+}
+
+// revealedAlpha returns how opaque a black square should be drawn, squares
+// cleared by a chirp are invisible and then fade back in
+func (g *Game) revealedAlpha(b image.Point) float64 {
+	t, ok := g.revealed[b]
+	if !ok {
+		return 1
+	}
+	if t > chirpFade {
+		return 0
+	}
+	return 1 - float64(t)/chirpFade
+}
+
+// drawChirp draws the ring of a chirp in progress onto the same surface as
+// the blackness
+func (g *Game) drawChirp(surface *ebiten.Image) {
+	if g.chirp == nil {
+		return
+	}
+	x, y := float64(g.chirp.Center.X), float64(g.chirp.Center.Y)
+	if g.rules.BlacknessSpace == BlacknessWorld {
+		x, y = g.cam.GetTranslation(x, y).GeoM.Apply(0, 0)
+	}
+	alpha := uint8(0xc0 * (1 - g.chirp.Radius/chirpRadius))
+	vector.StrokeCircle(surface,
+		float32(x), float32(y), float32(g.chirp.Radius), 2,
+		color.RGBA{alpha, alpha, alpha, alpha}, true,
+	)
+}
@@ -6,8 +6,6 @@ package cr1ckt
 
 import (
 	"image"
-
-	"github.com/solarlune/ldtkgo"
 )
 
 // TilesImpassible is a list of tiles you can't pass through while jumping
@@ -30,22 +28,56 @@ var TileSquishy = []int{
 	19, 20, // Floating in water
 }
 
-// Collides checks whether the Cricket is colliding with a tile
-func Collides(g *Game) *ldtkgo.Tile {
-	level := g.LDTKProject.Levels[g.Level]
-	tiles := level.Layers[LayerTile]
-	auto := level.Layers[LayerAuto]
+// Collides checks whether the Cricket is colliding with a tile on any of the
+// collision layers
+func Collides(g *Game) *MapTile {
 	hitbox := g.Cricket.Hitbox()
-	if c := OverlapsTiles(tiles.AllTiles(), hitbox, tiles.GridSize); c != nil {
-		return c
+	for _, layer := range g.collisionLayers() {
+		if c := OverlapsTiles(layer.Tiles, hitbox, layer.GridSize); c != nil {
+			return c
+		}
+	}
+	return nil
+}
+
+// groundGap is how far above the ground a standing cricket can be, landing
+// puts it back where it was before it hit the ground, which can be up to a
+// whole fall step above it
+const groundGap = 6
+
+// onGround checks whether there's a tile or a closed door just under the
+// Cricket for it to stand on
+func (g *Game) onGround() bool {
+	feet := g.Cricket.Hitbox()
+	below := image.Rect(feet.Min.X, feet.Max.Y, feet.Max.X, feet.Max.Y+groundGap)
+	for _, layer := range g.collisionLayers() {
+		if OverlapsTiles(layer.Tiles, below, layer.GridSize) != nil {
+			return true
+		}
 	}
-	return OverlapsTiles(auto.AllTiles(), hitbox, tiles.GridSize)
+	for _, d := range g.doors {
+		if !d.Open() && d.Rect.Overlaps(below) {
+			return true
+		}
+	}
+	return false
+}
+
+// CollidesDoor checks whether the Cricket is colliding with a closed door
+func CollidesDoor(g *Game) *Door {
+	hitbox := g.Cricket.Hitbox()
+	for _, d := range g.doors {
+		if !d.Open() && d.Rect.Overlaps(hitbox) {
+			return d
+		}
+	}
+	return nil
 }
 
 // OverlapsTiles checks for collisions on a given layer
 // This inner function is a workaround because we need to loop through both
 // Tiles and AutoTiles in exactly the same way
-func OverlapsTiles(ts []*ldtkgo.Tile, hitbox image.Rectangle, gridSize int) *ldtkgo.Tile {
+func OverlapsTiles(ts []*MapTile, hitbox image.Rectangle, gridSize int) *MapTile {
 	for _, v := range ts {
 		if v != nil && image.Rect(
 			v.Position[0], v.Position[1],
@@ -58,7 +90,7 @@ func OverlapsTiles(ts []*ldtkgo.Tile, hitbox image.Rectangle, gridSize int) *ldt
 }
 
 // Impassible checks whether a tile is impassible (true) or passible (false)
-func Impassible(tile *ldtkgo.Tile) bool {
+func Impassible(tile *MapTile) bool {
 	for _, t := range TilesImpassible {
 		if tile.ID == t {
 			return true
@@ -68,7 +100,7 @@ func Impassible(tile *ldtkgo.Tile) bool {
 }
 
 // Squishy checks whether a tile is squishy (true) or hard (false)
-func Squishy(tile *ldtkgo.Tile) bool {
+func Squishy(tile *MapTile) bool {
 	for _, t := range TileSquishy {
 		if tile.ID == t {
 			return true
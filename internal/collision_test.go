@@ -3,26 +3,24 @@ package cr1ckt
 import (
 	"image"
 	"testing"
-
-	"github.com/solarlune/ldtkgo"
 )
 
 func TestImpassible(t *testing.T) {
 	IDWater, IDEarth := 114, 0
-	if Impassible(&ldtkgo.Tile{ID: IDWater}) {
+	if Impassible(&MapTile{ID: IDWater}) {
 		t.Error("Water should be impassible")
 	}
-	if !Impassible(&ldtkgo.Tile{ID: IDEarth}) {
+	if !Impassible(&MapTile{ID: IDEarth}) {
 		t.Error("Earth should be impassible")
 	}
 }
 
 func TestSquishy(t *testing.T) {
 	IDMushroom, IDEarth := 15, 0
-	if !Squishy(&ldtkgo.Tile{ID: IDMushroom}) {
+	if !Squishy(&MapTile{ID: IDMushroom}) {
 		t.Error("Mushroom should be squishy")
 	}
-	if Squishy(&ldtkgo.Tile{ID: IDEarth}) {
+	if Squishy(&MapTile{ID: IDEarth}) {
 		t.Error("Earth should be hard")
 	}
 }
@@ -35,14 +33,14 @@ func rect16(x, y int) image.Rectangle {
 
 func TestOverlapsTiles(t *testing.T) {
 	const gridSize int = 16
-	tiles := []*ldtkgo.Tile{
+	tiles := []*MapTile{
 		{ID: 0, Position: []int{0, 0}},
 		{ID: 114, Position: []int{32, 48}},
 		{ID: 0, Position: []int{48, 48}},
 	}
 	cases := []struct {
 		hitbox  image.Rectangle
-		want    *ldtkgo.Tile
+		want    *MapTile
 		comment string
 	}{
 		{rect16(0, 0), tiles[0], "inside earth"},
@@ -0,0 +1,147 @@
+// Copyright 2021 Siôn le Roux.  All rights reserved.
+// Use of this source code is subject to an MIT-style
+// licence which can be found in the LICENSE file.
+
+package cr1ckt
+
+import (
+	"image"
+	"image/color"
+	"log"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+// DeathCause is what killed the cricket
+type DeathCause int
+
+const (
+	// DeathWater is when the cricket falls in water
+	DeathWater DeathCause = iota
+	// DeathEnemy is when the cricket is caught by something
+	DeathEnemy
+	// DeathOutOfBounds is when the cricket falls off the bottom of the level
+	DeathOutOfBounds
+)
+
+func (c DeathCause) String() string {
+	switch c {
+	case DeathWater:
+		return "water"
+	case DeathEnemy:
+		return "enemy"
+	case DeathOutOfBounds:
+		return "out-of-bounds"
+	}
+	return "unknown"
+}
+
+// Death is a record of how and where in the world the cricket died
+type Death struct {
+	Cause    DeathCause
+	Position image.Point
+	Level    int
+	Attempt  int
+}
+
+// Lengths of each part of the death sequence, in ticks
+const (
+	deathAnimTicks   = 30 // splash or squish
+	deathFreezeTicks = 15 // nothing happens
+	deathFadeTicks   = 30 // fade to black
+)
+
+// Dying is a death sequence that is in progress, when it finishes the cricket
+// respawns
+type Dying struct {
+	Death
+	Tick int
+}
+
+// Done returns whether the whole death sequence has played out
+func (d *Dying) Done() bool {
+	return d.Tick >= deathAnimTicks+deathFreezeTicks+deathFadeTicks
+}
+
+// Kill starts the death sequence for the cricket and records the death
+func (g *Game) Kill(cause DeathCause) {
+	death := Death{
+		Cause: cause,
+		Position: image.Pt(
+			g.Cricket.Position.X+g.Cricket.Width/2,
+			g.Cricket.Position.Y+g.Cricket.Image.Bounds().Dy(),
+		),
+		Level:   g.Level,
+		Attempt: g.attempt,
+	}
+	log.Println("Died from", death.Cause, "at", death.Position)
+	g.Deaths = append(g.Deaths, death)
+	if cause == DeathWater {
+		g.particles.Emit(&ParticleSplash, 16,
+			float64(death.Position.X), float64(death.Position.Y),
+		)
+	}
+	g.dying = &Dying{Death: death}
+	g.view.Shake.Add(0.6)
+	g.view.Shake.Freeze(6)
+}
+
+// DeathsInLevel returns how many times the cricket died in the current level
+// since it was last entered
+func (g *Game) DeathsInLevel() int {
+	var n int
+	for _, d := range g.Deaths {
+		if d.Level == g.Level && d.Attempt == g.attempt {
+			n++
+		}
+	}
+	return n
+}
+
+// updateDying moves the death sequence along and respawns the cricket when it
+// has finished
+func (g *Game) updateDying() {
+	g.dying.Tick++
+	if g.dying.Done() {
+		g.dying = nil
+		g.Respawn()
+	}
+}
+
+// drawDying draws the squish animation to the camera surface, in water the
+// cricket is gone and there's only the splash particles
+func (g *Game) drawDying() {
+	d := g.dying
+	if d.Cause == DeathWater {
+		return
+	}
+	t := math.Min(float64(d.Tick)/deathAnimTicks, 1)
+	x, y := g.cam.GetTranslation(
+		float64(d.Position.X), float64(d.Position.Y),
+	).GeoM.Apply(0, 0)
+
+	// Squish the cricket flat
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(-float64(g.Cricket.Width)/2, -float64(g.Cricket.Width))
+	op.GeoM.Scale(1+t/2, 1-t*0.8)
+	op.GeoM.Translate(x, y)
+	g.cam.Surface.DrawImage(g.Cricket.Image.SubImage(image.Rect(
+		0, 0, g.Cricket.Width, g.Cricket.Width,
+	)).(*ebiten.Image), op)
+}
+
+// drawDeathFade draws the black fade over the whole screen at the end of the
+// death sequence
+func (g *Game) drawDeathFade(screen *ebiten.Image) {
+	fade := g.dying.Tick - deathAnimTicks - deathFreezeTicks
+	if fade <= 0 {
+		return
+	}
+	alpha := uint8(math.Min(float64(fade)/deathFadeTicks, 1) * 0xff)
+	ebitenutil.DrawRect(screen,
+		0, 0, float64(g.Width), float64(g.Height),
+		color.RGBA{0, 0, 0, alpha},
+	)
+}
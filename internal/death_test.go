@@ -0,0 +1,20 @@
+package cr1ckt
+
+import "testing"
+
+func TestDeathsInLevel(t *testing.T) {
+	g := newTestGame(t, testLevel)
+	g.die(DeathWater)
+	g.die(DeathOutOfBounds)
+	if n := g.DeathsInLevel(); n != 2 {
+		t.Errorf("Died %d times after two deaths, want 2", n)
+	}
+	if c := g.Deaths[1].Cause; c != DeathOutOfBounds {
+		t.Errorf("Second death was from %s, want %s", c, DeathOutOfBounds)
+	}
+
+	g.Enter(&Exit{Level: g.Level})
+	if n := g.DeathsInLevel(); n != 0 {
+		t.Errorf("Died %d times after entering the level again, want 0", n)
+	}
+}
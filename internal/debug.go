@@ -17,7 +17,7 @@ var (
 )
 
 func debug(screen *ebiten.Image, g *Game) {
-	layer := g.LDTKProject.Levels[g.Level].Layers[LayerTile]
+	layer := g.collisionLayers()[0]
 	hitbox := g.Cricket.Hitbox()
 
 	var state string
@@ -35,17 +35,18 @@ func debug(screen *ebiten.Image, g *Game) {
 position%v - velocity%v: hitbox%v clip[%v]
 keypress:%v/%v
 jumps:%d
+deaths:%d
 level:%d
 anim:%v`,
 			ebiten.CurrentFPS(),
 			g.Cricket.Position,
 			g.Cricket.Velocity,
 			hitbox,
-			layer.TileAt(layer.ToGridPosition(
-				g.Cricket.Position.X, g.Cricket.Position.Y)),
+			layer.TileAt(g.Cricket.Position.X, g.Cricket.Position.Y),
 			debugLastJumpStrength,
 			g.Cricket.PrimeDuration,
 			debugNumberOfJumps,
+			len(g.Deaths),
 			g.Level,
 			state,
 		))
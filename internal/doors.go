@@ -0,0 +1,220 @@
+// Copyright 2021 Siôn le Roux.  All rights reserved.
+// Use of this source code is subject to an MIT-style
+// licence which can be found in the LICENSE file.
+
+package cr1ckt
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+func init() {
+	RegisterEntity("Door", func(g *Game, e *MapEntity) Entity {
+		return NewDoor(e, e.Iid)
+	})
+	RegisterEntity("Switch", func(g *Game, e *MapEntity) Entity {
+		return NewSwitch(e)
+	})
+	RegisterEntity("Key", func(g *Game, e *MapEntity) Entity {
+		return NewKey(e)
+	})
+}
+
+// Door is a solid block from a "Door" entity in LDtk that the cricket can't
+// pass through until it's opened, either by a Switch that references it or
+// shares its Group, or by bringing a Key to it if its NeedsKey field is set,
+// or both
+type Door struct {
+	Rect     image.Rectangle
+	Iid      string
+	Group    string // Set in LDtk, switches with the same group open the door
+	NeedsKey bool   // Set in LDtk, the door stays locked until given a key
+	Unlocked bool   // A key has been used on the door
+	Switched bool   // At least one switch references the door
+	Pressed  bool   // One of the switches that references the door is pressed
+}
+
+// NewDoor returns a new, closed Door covering the given LDtk entity
+func NewDoor(e *MapEntity, iid string) *Door {
+	d := &Door{Rect: entityRect(e), Iid: iid, Group: entityGroup(e)}
+	if p := e.PropertyByIdentifier("NeedsKey"); p != nil && !p.IsNull() {
+		d.NeedsKey = p.AsBool()
+	}
+	return d
+}
+
+// Open returns whether the cricket can pass through the door
+func (d *Door) Open() bool {
+	return (!d.NeedsKey || d.Unlocked) && (!d.Switched || d.Pressed)
+}
+
+// Hitbox returns the area of the door
+func (d *Door) Hitbox() image.Rectangle {
+	return d.Rect
+}
+
+// Draw draws the door to the camera surface, faded out once it's open and
+// with a keyhole while it's locked
+func (d *Door) Draw(g *Game) {
+	if d.Open() {
+		g.drawRect(d.Rect, color.RGBA{0x5a, 0x3a, 0x22, 0x40})
+		return
+	}
+	g.drawRect(d.Rect, color.RGBA{0x2b, 0x1d, 0x14, 0xff})
+	g.drawRect(d.Rect.Inset(2), color.RGBA{0x5a, 0x3a, 0x22, 0xff})
+	if d.NeedsKey && !d.Unlocked {
+		c := d.Rect.Min.Add(d.Rect.Size().Div(2))
+		g.drawRect(image.Rect(c.X-2, c.Y-2, c.X+2, c.Y+2), color.RGBA{0xf2, 0xc1, 0x3a, 0xff})
+	}
+}
+
+// Switch is a pressure plate from a "Switch" entity in LDtk, when the cricket
+// lands on it, it opens the doors referenced in its Doors field and the doors
+// in the same Group
+type Switch struct {
+	Rect    image.Rectangle
+	Refs    []string // Instance IDs of the doors it opens
+	Group   string   // Doors with the same group are opened too
+	Doors   []*Door
+	Pressed bool
+}
+
+// NewSwitch returns a new Switch covering the given LDtk entity, it's wired
+// up to the doors its Doors entity reference field points to and the doors in
+// its Group by Link
+func NewSwitch(e *MapEntity) *Switch {
+	return &Switch{Rect: entityRect(e), Refs: EntityRefs(e, "Doors"), Group: entityGroup(e)}
+}
+
+// entityGroup returns the entity's Group field, which wires switches to doors
+// in projects from before LDtk 1.0 that don't have entity references
+func entityGroup(e *MapEntity) string {
+	if p := e.PropertyByIdentifier("Group"); p != nil && !p.IsNull() {
+		return p.AsString()
+	}
+	return ""
+}
+
+// Link wires the switch up to the doors it references or shares a group with
+func (s *Switch) Link(g *Game, m *EntityManager) {
+	for _, d := range EntitiesOf[*Door](m) {
+		if s.links(d) {
+			d.Switched = true
+			s.Doors = append(s.Doors, d)
+		}
+	}
+}
+
+// links returns whether the switch opens the door
+func (s *Switch) links(d *Door) bool {
+	if s.Group != "" && s.Group == d.Group {
+		return true
+	}
+	for _, iid := range s.Refs {
+		if d.Iid != "" && d.Iid == iid {
+			return true
+		}
+	}
+	return false
+}
+
+// Hitbox returns the area of the switch
+func (s *Switch) Hitbox() image.Rectangle {
+	return s.Rect
+}
+
+// Touch presses the switch if the cricket is standing on it
+func (s *Switch) Touch(g *Game) {
+	if !s.Pressed && !g.Cricket.Jumping {
+		s.Press()
+	}
+}
+
+// Draw draws the switch to the camera surface, red until it's pressed down
+func (s *Switch) Draw(g *Game) {
+	r := s.Rect
+	r.Min.Y = r.Max.Y - 4
+	clr := color.RGBA{0xb0, 0x30, 0x30, 0xff}
+	if s.Pressed {
+		r.Min.Y += 2
+		clr = color.RGBA{0x7e, 0xc8, 0x50, 0xff}
+	}
+	g.drawRect(r, clr)
+}
+
+// Press presses the switch down so the doors it's wired to open
+func (s *Switch) Press() {
+	s.Pressed = true
+	for _, d := range s.Doors {
+		d.Pressed = true
+	}
+}
+
+// Key is a "Key" entity from LDtk that the cricket picks up and carries to a
+// door that needs one
+type Key struct {
+	Position image.Point
+	Carried  bool
+	Used     bool // The key was used up to unlock a door
+}
+
+// NewKey returns a new Key at the position of the given LDtk entity
+func NewKey(e *MapEntity) *Key {
+	return &Key{Position: entityRect(e).Min}
+}
+
+// Hitbox returns a correctly positioned rectangular hitbox for collision
+// detection with the Key
+func (k *Key) Hitbox() image.Rectangle {
+	return image.Rect(0, 0, 16, 16).Add(k.Position)
+}
+
+// Touch picks the key up
+func (k *Key) Touch(g *Game) {
+	k.Carried = true
+}
+
+// Draw draws the key to the camera surface where it is, or floating just
+// above the cricket's head while it's carried
+func (k *Key) Draw(g *Game) {
+	if k.Used {
+		return
+	}
+	pos := k.Position
+	if k.Carried {
+		pos = g.Cricket.Position.Add(image.Pt(g.Cricket.Width/2-8, 0))
+	}
+	g.cam.Surface.DrawImage(g.keyImg, g.cam.GetTranslation(float64(pos.X), float64(pos.Y)))
+}
+
+// unlockDoor uses up a key the cricket is holding if the door needs one, it
+// returns whether the door is open now
+func (g *Game) unlockDoor(d *Door) bool {
+	if d.NeedsKey && !d.Unlocked {
+		if k := g.heldKey(); k != nil {
+			k.Used = true
+			d.Unlocked = true
+		}
+	}
+	return d.Open()
+}
+
+// drawRect draws a rectangle of the level to the camera surface
+func (g *Game) drawRect(r image.Rectangle, clr color.Color) {
+	x, y := g.cam.GetTranslation(float64(r.Min.X), float64(r.Min.Y)).GeoM.Apply(0, 0)
+	ebitenutil.DrawRect(g.cam.Surface, x, y, float64(r.Dx()), float64(r.Dy()), clr)
+}
+
+// heldKey returns a key the cricket is carrying that hasn't been used yet, or
+// nil if it doesn't have one
+func (g *Game) heldKey() *Key {
+	for _, k := range g.keys {
+		if k.Carried && !k.Used {
+			return k
+		}
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package cr1ckt
+
+import "testing"
+
+func TestSwitchLink(t *testing.T) {
+	cases := []struct {
+		sw      Switch
+		door    Door
+		want    bool
+		comment string
+	}{
+		{Switch{Refs: []string{"a"}}, Door{Iid: "a"}, true, "referenced door"},
+		{Switch{Refs: []string{"a"}}, Door{Iid: "b"}, false, "other door"},
+		{Switch{Refs: []string{""}}, Door{}, false, "door without an iid"},
+		{Switch{Group: "gate"}, Door{Group: "gate"}, true, "door in the same group"},
+		{Switch{Group: "gate"}, Door{Group: "shed"}, false, "door in another group"},
+		{Switch{}, Door{}, false, "neither in a group"},
+		{Switch{Refs: []string{"a"}, Group: "gate"}, Door{Iid: "a", Group: "gate"}, true, "door linked both ways"},
+	}
+	for _, c := range cases {
+		m := &EntityManager{All: []Entity{&c.door}}
+		c.sw.Link(nil, m)
+		if c.door.Switched != c.want {
+			t.Errorf("Switching %s is %v, want %v", c.comment, c.door.Switched, c.want)
+		}
+		if c.want && len(c.sw.Doors) != 1 {
+			t.Errorf("Switch for %s has %d doors, want 1", c.comment, len(c.sw.Doors))
+		}
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2021 Siôn le Roux.  All rights reserved.
+// Use of this source code is subject to an MIT-style
+// licence which can be found in the LICENSE file.
+
+package cr1ckt
+
+import (
+	"image"
+	"log"
+)
+
+// Entity is something in a level made from a MapEntity, all it has to have
+// is the area it covers, the other interfaces below add behaviour to it
+type Entity interface {
+	Hitbox() image.Rectangle
+}
+
+// Updater is an Entity that does something every tick, including while the
+// cricket is dying and during the level intro, so it's for things going on in
+// the background rather than things that move the cricket
+type Updater interface {
+	Update(g *Game)
+}
+
+// Drawer is an Entity that draws itself to the camera surface, entities that
+// don't are drawn with their tile from the map if they have one
+type Drawer interface {
+	Draw(g *Game)
+}
+
+// Toucher is an Entity that does something when the cricket touches it
+type Toucher interface {
+	Touch(g *Game)
+}
+
+// Linker is an Entity that needs to find other entities once they've all been
+// made, e.g. through entity reference fields
+type Linker interface {
+	Link(g *Game, m *EntityManager)
+}
+
+// EntityFactory makes the Entity for a map entity, or returns nil if it
+// shouldn't have one, which also stops it being drawn with its tile
+type EntityFactory func(g *Game, e *MapEntity) Entity
+
+// entityFactories are the registered factories by map entity identifier
+var entityFactories = map[string]EntityFactory{}
+
+// RegisterEntity sets the factory that makes the Entity for every map entity
+// with the given identifier, each identifier can only be registered once
+func RegisterEntity(identifier string, factory EntityFactory) {
+	if _, ok := entityFactories[identifier]; ok {
+		log.Fatalf("entity %s registered twice\n", identifier)
+	}
+	entityFactories[identifier] = factory
+}
+
+// EntityManager holds all the entities in the current level
+type EntityManager struct {
+	All []Entity
+}
+
+// loadEntities makes the Entity for every map entity in the current level
+// from its registered factory, or a Sprite if it has no factory but has a tile
+func (g *Game) loadEntities() *EntityManager {
+	m := &EntityManager{}
+	layers := g.entityLayers()
+	// Like in LDtk the bottom layer comes last
+	for i := len(layers) - 1; i >= 0; i-- {
+		layer := layers[i]
+		for _, e := range layer.Entities {
+			var entity Entity
+			if factory, ok := entityFactories[e.Identifier]; ok {
+				if entity = factory(g, e); entity == nil {
+					continue
+				}
+				m.All = append(m.All, entity)
+			}
+			if _, ok := entity.(Drawer); ok || !layer.Visible {
+				continue
+			}
+			if s := g.NewSprite(e, layer); s != nil {
+				m.All = append(m.All, s)
+			}
+		}
+	}
+	for _, entity := range m.All {
+		if l, ok := entity.(Linker); ok {
+			l.Link(g, m)
+		}
+	}
+	return m
+}
+
+// Update updates every entity that does something every tick
+func (m *EntityManager) Update(g *Game) {
+	for _, entity := range m.All {
+		if u, ok := entity.(Updater); ok {
+			u.Update(g)
+		}
+	}
+}
+
+// Draw draws every entity that draws itself
+func (m *EntityManager) Draw(g *Game) {
+	for _, entity := range m.All {
+		if d, ok := entity.(Drawer); ok {
+			d.Draw(g)
+		}
+	}
+}
+
+// Touch lets every entity the cricket's hitbox overlaps know it's touching
+// it, stopping early if one of them changes the level or is an exit the
+// cricket starts going through
+func (m *EntityManager) Touch(g *Game, hitbox image.Rectangle) {
+	for _, entity := range m.All {
+		if t, ok := entity.(Toucher); ok && entity.Hitbox().Overlaps(hitbox) {
+			t.Touch(g)
+			if g.entities != m || g.win || g.exiting != nil {
+				return
+			}
+		}
+	}
+}
+
+// EntitiesOf returns all the entities of one type from the manager
+func EntitiesOf[T Entity](m *EntityManager) []T {
+	var found []T
+	for _, entity := range m.All {
+		if t, ok := entity.(T); ok {
+			found = append(found, t)
+		}
+	}
+	return found
+}
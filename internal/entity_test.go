@@ -0,0 +1,85 @@
+package cr1ckt
+
+import (
+	"image"
+	"strings"
+	"testing"
+)
+
+// testBeetle is an entity that counts what the game asks it to do
+type testBeetle struct {
+	Rect                    image.Rectangle
+	updates, draws, touches int
+	exit                    bool // Touching it starts going through an exit
+}
+
+func (b *testBeetle) Hitbox() image.Rectangle { return b.Rect }
+func (b *testBeetle) Update(g *Game)          { b.updates++ }
+func (b *testBeetle) Draw(g *Game)            { b.draws++ }
+func (b *testBeetle) Touch(g *Game) {
+	b.touches++
+	if b.exit {
+		g.exiting = &Exiting{Exit: &Exit{Level: -1}}
+	}
+}
+
+func init() {
+	RegisterEntity("Beetle", func(g *Game, e *MapEntity) Entity {
+		return &testBeetle{Rect: entityRect(e)}
+	})
+}
+
+func TestLoadEntities(t *testing.T) {
+	// Both have tiles, but only the flower has no factory to make it
+	level := strings.Replace(testLevel, "</objectgroup>", `<object id="7" type="Beetle" gid="10" x="32" y="144" width="16" height="16"/>
+  <object id="8" type="Flower" gid="10" x="64" y="144" width="16" height="16"/>
+ </objectgroup>`, 1)
+	g := newTestGame(t, level)
+
+	beetles := EntitiesOf[*testBeetle](g.entities)
+	if len(beetles) != 1 || beetles[0].Rect != image.Rect(32, 128, 48, 144) {
+		t.Fatalf("Beetles are %v, want one at 32, 128", beetles)
+	}
+	sprites := EntitiesOf[*Sprite](g.entities)
+	if len(sprites) != 1 || sprites[0].Entity.Identifier != "Flower" {
+		t.Errorf("Sprites are %v, want only the flower", sprites)
+	}
+	for _, e := range g.entities.All {
+		if e == nil {
+			t.Error("The cricket marker shouldn't be an entity")
+		}
+	}
+}
+
+func TestEntityManager(t *testing.T) {
+	g := &Game{}
+	near := &testBeetle{Rect: image.Rect(0, 0, 16, 16), exit: true}
+	behind := &testBeetle{Rect: image.Rect(0, 0, 16, 16)}
+	far := &testBeetle{Rect: image.Rect(100, 100, 116, 116)}
+	m := &EntityManager{All: []Entity{far, near, behind}}
+	g.entities = m
+
+	m.Update(g)
+	m.Draw(g)
+	for i, b := range m.All {
+		if b := b.(*testBeetle); b.updates != 1 || b.draws != 1 {
+			t.Errorf("Beetle %d was updated %d and drawn %d times, want once each", i, b.updates, b.draws)
+		}
+	}
+
+	m.Touch(g, image.Rect(8, 8, 24, 24))
+	cases := []struct {
+		beetle  *testBeetle
+		want    int
+		comment string
+	}{
+		{far, 0, "far away"},
+		{near, 1, "touched"},
+		{behind, 0, "after an exit"},
+	}
+	for _, c := range cases {
+		if c.beetle.touches != c.want {
+			t.Errorf("Beetle %s was touched %d times, want %d", c.comment, c.beetle.touches, c.want)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright 2021 Siôn le Roux.  All rights reserved.
+// Use of this source code is subject to an MIT-style
+// licence which can be found in the LICENSE file.
+
+package cr1ckt
+
+import (
+	"image"
+	"log"
+)
+
+func init() {
+	RegisterEntity("Exit", func(g *Game, e *MapEntity) Entity {
+		return NewExit(e, g.Levels, g.Level)
+	})
+	// These only mark where the cricket starts, which the game draws itself
+	marker := func(g *Game, e *MapEntity) Entity {
+		return nil
+	}
+	RegisterEntity("Cricket", marker)
+	RegisterEntity("Spawn", marker)
+}
+
+// Exit is a way out of the level from an "Exit" entity in LDtk, it leads to
+// the level in its Target field, either by index or by level identifier, and
+// the cricket starts there at the Spawn entity named in its Spawn field
+type Exit struct {
+	Rect  image.Rectangle
+	Level int    // Index of the level the exit leads to, -1 to win the game
+	Spawn string // Name of the Spawn entity to start at, empty for the Cricket
+}
+
+// NewExit returns a new Exit covering the given LDtk entity, an exit with no
+// Target or one that leads back to its own level wins the game instead
+func NewExit(e *MapEntity, levels []*MapLevel, current int) *Exit {
+	exit := &Exit{Rect: entityRect(e), Level: -1}
+
+	if p := e.PropertyByIdentifier("Target"); p != nil && !p.IsNull() {
+		switch v := p.Value.(type) {
+		case float64:
+			exit.Level = int(v)
+		case string:
+			exit.Level = levelIndex(levels, v)
+		}
+	}
+	if exit.Level < 0 || exit.Level >= len(levels) || exit.Level == current {
+		if exit.Level >= len(levels) {
+			log.Println("Exit target level", exit.Level, "doesn't exist")
+		}
+		exit.Level = -1
+	}
+
+	if p := e.PropertyByIdentifier("Spawn"); p != nil && !p.IsNull() {
+		exit.Spawn = p.AsString()
+	}
+
+	return exit
+}
+
+// levelIndex returns the index of the level with the given identifier in the
+// levels, or -1 if there isn't one
+func levelIndex(levels []*MapLevel, identifier string) int {
+	for i, level := range levels {
+		if level.Identifier == identifier {
+			return i
+		}
+	}
+	log.Printf("Level %q doesn't exist\n", identifier)
+	return -1
+}
+
+// Hitbox returns the area of the exit the cricket has to touch
+func (exit *Exit) Hitbox() image.Rectangle {
+	return exit.Rect
+}
+
+// Update gives off a burst of pollen every so often to show where the exit is
+func (exit *Exit) Update(g *Game) {
+	if g.particles.tick%pollenTicks == 0 {
+		c := exit.Rect.Min.Add(exit.Rect.Size().Div(2))
+		g.particles.Emit(&ParticlePollen, 8, float64(c.X), float64(c.Y))
+	}
+}
+
+// Lengths of the burst when the cricket reaches an exit
+const (
+	exitBurst      = 40 // Grains of pollen
+	exitBurstTicks = 30 // Ticks before the level changes or the game is won
+)
+
+// Exiting is the cricket going through an exit, it stops there for a moment
+// in a burst of pollen so you can see it got there
+type Exiting struct {
+	Exit *Exit
+	Tick int
+}
+
+// Touch bursts into pollen and starts going through the exit
+func (exit *Exit) Touch(g *Game) {
+	if g.exiting != nil {
+		return
+	}
+	g.saveSeeds()
+	c := exit.Rect.Min.Add(exit.Rect.Size().Div(2))
+	g.particles.Emit(&ParticlePollen, exitBurst, float64(c.X), float64(c.Y))
+	g.exiting = &Exiting{Exit: exit}
+}
+
+// updateExiting waits for the burst of pollen and then goes to the level the
+// exit leads to, or wins the game
+func (g *Game) updateExiting() {
+	g.exiting.Tick++
+	if g.exiting.Tick < exitBurstTicks {
+		return
+	}
+	exit := g.exiting.Exit
+	g.exiting = nil
+	if exit.Level < 0 {
+		log.Println("Found the exit, you win!")
+		g.win = true
+		return
+	}
+	log.Println("Found an exit to level", exit.Level)
+	g.Enter(exit)
+}
+
+// SpawnPoint returns where the cricket should start in the current level,
+// which is the Spawn entity with the given Name field if there is one,
+// otherwise the Cricket entity, or where it came in from a neighbouring level
+// if the level doesn't have one
+func (g *Game) SpawnPoint(name string) []int {
+	if name != "" {
+		for _, e := range g.EntitiesByIdentifier("Spawn") {
+			p := e.PropertyByIdentifier("Name")
+			if p != nil && !p.IsNull() && p.AsString() == name {
+				return e.Position
+			}
+		}
+		log.Printf("Spawn %q not found, using Cricket\n", name)
+	}
+	if e := g.EntityByIdentifier("Cricket"); e != nil {
+		return e.Position
+	}
+	if g.entry != nil {
+		return g.entry
+	}
+	log.Printf("level %s has no Cricket, starting in the corner\n",
+		g.Levels[g.Level].Identifier)
+	return []int{0, 0}
+}
+
+// Enter switches to the level an exit leads to, starting at its spawn point
+func (g *Game) Enter(exit *Exit) {
+	g.attempt++
+	g.entry = nil
+	g.Reset(exit.Level)
+	if exit.Spawn != "" {
+		g.Cricket = NewCricket(g.SpawnPoint(exit.Spawn))
+	}
+}
@@ -0,0 +1,62 @@
+package cr1ckt
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewExit(t *testing.T) {
+	levels := []*MapLevel{{Identifier: "Meadow"}, {Identifier: "Pond"}, {Identifier: "Burrow"}}
+	cases := []struct {
+		target    interface{}
+		spawn     interface{}
+		wantLevel int
+		wantSpawn string
+		comment   string
+	}{
+		{nil, nil, -1, "", "no target"},
+		{2.0, nil, 2, "", "target by index"},
+		{"Pond", "Lilypad", 1, "Lilypad", "target by name with a spawn"},
+		{"Desert", nil, -1, "", "target that doesn't exist"},
+		{3.0, nil, -1, "", "index past the last level"},
+		{-2.0, nil, -1, "", "negative index"},
+		{"Meadow", nil, -1, "", "target of its own level"},
+		{1.0, "", 1, "", "empty spawn"},
+	}
+	for _, c := range cases {
+		e := &MapEntity{Position: []int{0, 0}, Width: 16, Height: 16, Properties: Properties{
+			{Identifier: "Target", Value: c.target},
+			{Identifier: "Spawn", Value: c.spawn},
+		}}
+		exit := NewExit(e, levels, 0)
+		if exit.Level != c.wantLevel || exit.Spawn != c.wantSpawn {
+			t.Errorf("Exit with %s leads to level %d at %q, want %d at %q",
+				c.comment, exit.Level, exit.Spawn, c.wantLevel, c.wantSpawn)
+		}
+	}
+}
+
+func TestSpawnPoint(t *testing.T) {
+	level := strings.Replace(testLevel, "</objectgroup>", `<object id="7" type="Spawn" x="288" y="112" width="16" height="16">
+   <properties>
+    <property name="Name" value="Gate"/>
+   </properties>
+  </object>
+ </objectgroup>`, 1)
+	g := newTestGame(t, level)
+	cases := []struct {
+		name    string
+		want    []int
+		comment string
+	}{
+		{"", []int{16, 96}, "no spawn"},
+		{"Gate", []int{288, 112}, "named spawn"},
+		{"Cellar", []int{16, 96}, "missing spawn"},
+	}
+	for _, c := range cases {
+		if got := g.SpawnPoint(c.name); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Spawn point with %s is %v, want %v", c.comment, got, c.want)
+		}
+	}
+}
@@ -6,41 +6,99 @@ package cr1ckt
 
 import (
 	"image/png"
+	"io/fs"
 	"io/ioutil"
 	"log"
+	"path"
+	"strings"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/audio/vorbis"
+	"github.com/hajimehoshi/ebiten/v2/audio/wav"
 	"github.com/hajimehoshi/ebiten/v2/examples/resources/fonts"
-	"github.com/solarlune/ldtkgo"
 	"golang.org/x/image/font"
 	"golang.org/x/image/font/opentype"
 )
 
-// Load an project from embedded FS into an LDtk Project object
-func loadMaps(name string) *ldtkgo.Project {
-	log.Printf("loading %s\n", name)
-
-	file, err := assets.Open(name)
-	if err != nil {
-		log.Fatalf("error opening file %s: %v\n", name, err)
+// loadMaps loads every map file matching the patterns and puts all their
+// levels in one list, in the order of the patterns and then by file name
+func loadMaps(patterns []string) MapSource {
+	var maps MapSources
+	loaded := make(map[string]bool)
+	for _, pattern := range patterns {
+		names, err := fs.Glob(assets, path.Join("assets", pattern))
+		if err != nil {
+			log.Fatalf("error finding map files %s: %v\n", pattern, err)
+		}
+		for _, name := range names {
+			if loaded[name] {
+				continue
+			}
+			loaded[name] = true
+			maps = append(maps, loadMapFile(name))
+		}
 	}
-	defer file.Close()
+	if len(maps.Levels()) == 0 {
+		log.Fatalf("no levels found in map files %v\n", patterns)
+	}
+	return maps
+}
 
-	data, err := ioutil.ReadAll(file)
+// Load a map file from embedded FS into a MapSource, depending on which
+// editor it was made with
+func loadMapFile(name string) MapSource {
+	data, err := readAsset(name)
 	if err != nil {
 		log.Fatalf("error reading from file %s: %v\n", name, err)
 	}
 
-	// Load the LDtk Project
-	maps, err := ldtkgo.Read(data)
+	switch path.Ext(name) {
+	case ".tmx", ".tmj":
+		maps, err := ReadTiled(name, data, readAsset)
+		if err != nil {
+			log.Fatalf("error parsing file %s as Tiled map: %v\n", name, err)
+		}
+		return maps
+	}
+	maps, err := ReadLDTK(name, data, readAsset)
 	if err != nil {
 		log.Fatalf("error parsing file %s as LDtk Project: %v\n", name, err)
 	}
-
 	return maps
 }
 
+// readAsset reads a whole file from the embedded FS
+func readAsset(name string) ([]byte, error) {
+	log.Printf("loading %s\n", name)
+
+	file, err := assets.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ioutil.ReadAll(file)
+}
+
+// assetsPath returns a path in the embedded FS relative to the assets folder,
+// which is where tilesets are loaded from
+func assetsPath(name string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(name, "assets"), "/")
+}
+
+// loadLevel reads the layers of a level that weren't read with the rest of
+// its map file the first time it's needed
+func (g *Game) loadLevel(i int) {
+	level := g.Levels[i]
+	if level.Loaded {
+		return
+	}
+	if err := g.Maps.LoadLevel(level); err != nil {
+		log.Fatalf("error loading level %s: %v\n", level.Identifier, err)
+	}
+	checkLevel(level)
+}
+
 // LoadImage loads an ebiten.Image given the name of a file in the embedded fs
 func LoadImage(name string) *ebiten.Image {
 	return loadImage(name)
@@ -82,6 +140,30 @@ func loadSoundFile(name string, sampleRate int) *vorbis.Stream {
 	return music
 }
 
+// Load a WAV sound file and return its decoded bytes, resampled to the given
+// sample rate, so that short sounds can be played over and over
+func loadWavFile(name string, sampleRate int) []byte {
+	log.Printf("loading %s\n", name)
+
+	file, err := assets.Open(name)
+	if err != nil {
+		log.Fatalf("error opening file %s: %v\n", name, err)
+	}
+	defer file.Close()
+
+	sound, err := wav.DecodeWithSampleRate(sampleRate, file)
+	if err != nil {
+		log.Fatalf("error decoding file %s as WAV: %v\n", name, err)
+	}
+
+	data, err := ioutil.ReadAll(sound)
+	if err != nil {
+		log.Fatalf("error reading from file %s: %v\n", name, err)
+	}
+
+	return data
+}
+
 func loadFont(size int) font.Face {
 	fontdata, err := opentype.Parse(fonts.PressStart2P_ttf)
 	if err != nil {
@@ -7,10 +7,11 @@ package cr1ckt
 import (
 	"embed"
 	"errors"
+	"fmt"
 	"image"
 	"image/color"
 	"log"
-	"math/rand"
+	"math"
 
 	"golang.org/x/image/font"
 	"gopkg.in/ini.v1"
@@ -19,22 +20,13 @@ import (
 	"github.com/hajimehoshi/ebiten/v2/audio"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
 	camera "github.com/melonfunction/ebiten-camera"
-	"github.com/solarlune/ldtkgo"
 )
 
 //go:embed assets/*
 var assets embed.FS
 
-// LayerEntities is the layer to use for entity positions
-const LayerEntities int = 0
-
-// LayerAuto is the layer to check for auto-tile collisions
-const LayerAuto int = 1
-
-// LayerTile is the layer to check for tile collisions
-const LayerTile int = 2
-
 // VelocityDenominator is by how much to divide the time the jump was primed to
 // get the jump velocity
 var VelocityDenominator int = 10
@@ -51,14 +43,77 @@ var MinPrime int = 1
 // to jump for, it avoids you jumping off the screen
 var MaxPrime int = 5
 
+// Gravity is how strong gravity is as a percentage, at 100 the cricket's
+// upwards velocity goes down by one every time it moves
+var Gravity int = 100
+
 // DebugMode sets whether to display additional debugging info on the screen
 // during playing the game or not
 var DebugMode bool = false
 
-// BlacknessFactor sets after how many jumps the amount of blackness that's
-// added per jump should be doubled
+// BlacknessFactor sets how fast blackness grows, for most modes it's after how
+// many jumps the amount of blackness that's added per jump should go up
 var BlacknessFactor int = 10
 
+// BlacknessMode is the name of the BlacknessCurve to use, one of classic,
+// exponential, linear, time or off
+var BlacknessMode string = "classic"
+
+// BlacknessSpace is whether blackness stays put on the screen or on the level,
+// it's either BlacknessScreen or BlacknessWorld
+var BlacknessSpace string = BlacknessScreen
+
+// ChirpUses is how many times the cricket can chirp in each level
+var ChirpUses int = 3
+
+// CameraLerp is how much of the way to its target the camera moves each tick,
+// 1 snaps straight there and smaller numbers are smoother
+var CameraLerp float64 = 0.15
+
+// CameraDeadzoneX is how far the cricket can move sideways from the middle of
+// the screen before the camera starts following it
+var CameraDeadzoneX int = 48
+
+// CameraDeadzoneY is how far the cricket can move up or down from the middle
+// of the screen before the camera starts following it
+var CameraDeadzoneY int = 32
+
+// CameraLookAhead is how far ahead of the cricket the camera looks in the
+// direction it's facing
+var CameraLookAhead int = 64
+
+// CameraPrimeZoom is how much the camera zooms out while priming a jump at full
+// strength, 0.1 shows 10% more of the level
+var CameraPrimeZoom float64 = 0.1
+
+// ShowMinimap sets whether to show the minimap in the corner of the screen
+var ShowMinimap bool = true
+
+// HardMode takes away the minimap and the level overview, so the blackness is
+// the only thing between you and the exit
+var HardMode bool = false
+
+// ScreenShake is how strongly the screen shakes on impacts, 1 is normal and 0
+// turns shaking off for anyone who finds it uncomfortable
+var ScreenShake float64 = 1
+
+// HitStop sets whether the game freezes for a moment on big impacts
+var HitStop bool = true
+
+// CollisionLayers are the identifiers of the layers the cricket collides with,
+// checked in this order, they can use wildcards like "Tiles*" to match any
+// number of layers
+var CollisionLayers = []string{"Tiles*", "IntGrid*"}
+
+// EntityLayers are the identifiers of the layers entities are loaded from,
+// they can use wildcards like "Entities*" to match any number of layers
+var EntityLayers = []string{"Entities*"}
+
+// MapFiles are the LDtk projects (.ldtk) and Tiled maps (.tmx or .tmj) in the
+// assets folder whose levels are played, one file after another, they can use
+// wildcards like "packs/*.ldtk" to load every bonus pack there is
+var MapFiles = []string{"maps.ldtk", "packs/*.ldtk", "packs/*.tmx", "packs/*.tmj"}
+
 // JumpPress are the different jump states for controls
 const (
 	JumpPressNone int = iota
@@ -75,14 +130,49 @@ type Game struct {
 	Wait         int
 	WaitTime     int
 	TileRenderer *TileRenderer
-	LDTKProject  *ldtkgo.Project
+	Maps         MapSource
+	Levels       []*MapLevel
 	Level        int
 	Loading      bool
+	Deaths       []Death
+	attempt      int // Goes up every time a level is entered, but not on respawns
 	touchIDs     []ebiten.TouchID
 	blackness    Blackness
-	blackFactor  int
-	bg, fruit    *ebiten.Image
+	blackCurve   BlacknessCurve
+	ticks        int
+	parallaxSets map[string][]ParallaxLayer
+	parallaxImgs map[string]*ebiten.Image
+	parallax     []*ParallaxLayer
+	parallaxSet  string          // The name of the set the parallax layers are from
+	parallaxAt   image.Rectangle // The level they line up with, in world pixels
+	seedImg      *ebiten.Image
+	seeds        Seeds
+	flagImg      *ebiten.Image
+	respawn      *RunState
+	entry        []int // Where the cricket came into the level from a neighbour
+	entities     *EntityManager
+	doors        []*Door
+	keys         []*Key
+	keyImg       *ebiten.Image
+	exits        []*Exit
+	zones        []*Zone
+	rules        Rules
+	audio        *audio.Context
+	chirp        *Chirp
+	chirpWait    int
+	chirpsLeft   int
+	chirpPlayer  *audio.Player
+	revealed     map[image.Point]int
+	fall         int
+	dying        *Dying
+	exiting      *Exiting
+	save         *Save
 	cam          *camera.Camera
+	view         *CameraController // Decides where cam looks and how far it zooms
+	cameraZones  []*CameraZone
+	intro        *Intro
+	particles    Particles
+	minimap      *ebiten.Image
 	win          bool
 	fontBig      font.Face
 	fontSmall    font.Face
@@ -99,53 +189,54 @@ func NewGame(game *Game) {
 	// 		ebitenRenderer = renderer.NewEbitenRenderer(renderer.NewDiskLoader("assets"))
 	// 	} else {
 	log.Println("Using embedded map data...")
-	ldtkProject := loadMaps("assets/maps.ldtk")
+	maps := loadMaps(MapFiles)
+	checkLayers(maps.Levels())
 	renderer = NewTileRenderer(&EmbedLoader{"assets"})
 	// }
 
 	game.TileRenderer = renderer
-	game.LDTKProject = ldtkProject
-	game.fruit = loadImage("assets/fruit.png")
+	game.Maps = maps
+	game.Levels = maps.Levels()
+	game.seedImg = loadImage("assets/seed.png")
+	game.flagImg = loadImage("assets/checkpoint.png")
+	game.keyImg = loadImage("assets/key.png")
+	game.save = LoadSave(SaveFile)
 	game.cam = camera.NewCamera(game.Width, game.Height, 0, 0, 0, 1)
-	game.Cricket = NewCricket(game.EntityByIdentifier("Cricket").Position)
+	game.view = NewCameraController()
+	game.loadLevel(game.Level)
+	game.rules = LevelRules(game.Levels[game.Level])
+	game.blackCurve = NewBlacknessCurve(game.rules.BlacknessMode, game.rules.BlacknessFactor)
+	game.Cricket = NewCricket(game.SpawnPoint(""))
+	game.loadLevelEntities()
+	game.startIntro()
 	game.blackness = make(map[image.Point]bool)
+	game.revealed = make(map[image.Point]int)
+	game.chirpsLeft = game.rules.ChirpUses
 	game.fontBig = loadFont(32)
 	game.fontSmall = loadFont(16)
 
-	background := loadImage("assets/background.png")
-	bg := ebiten.NewImage(
-		game.LDTKProject.Levels[game.Level].Width,
-		game.LDTKProject.Levels[game.Level].Height,
-	)
-	bg.Fill(game.LDTKProject.Levels[game.Level].BGColor)
-	bg.DrawImage(background, &ebiten.DrawImageOptions{})
-
-	// Render map
-	game.TileRenderer.Render(game.LDTKProject.Levels[game.Level])
-	for _, layer := range game.TileRenderer.RenderedLayers {
-		bg.DrawImage(layer.Image, &ebiten.DrawImageOptions{})
-	}
-	for _, v := range game.LDTKProject.Levels[game.Level].Layers[LayerEntities].Entities {
-		if v.Identifier == "Exit" {
-			op := &ebiten.DrawImageOptions{}
-			op.GeoM.Translate(float64(v.Position[0]), float64(v.Position[1]))
-			bg.DrawImage(game.fruit, op)
-		}
-		// 23
-	}
-	game.bg = bg
+	game.parallaxSets = loadParallaxSets(ParallaxFile)
+	game.parallaxImgs = make(map[string]*ebiten.Image)
+	game.renderLevel()
+	game.startParallax()
 
 	// Music
 	const sampleRate int = 44100       // assuming "normal" sample rate
 	const introLength int64 = 10113930 // pre-calculated from music editor
+	game.audio = audio.NewContext(sampleRate)
 	music := loadSoundFile("assets/music.ogg", sampleRate)
 	musicLoop := audio.NewInfiniteLoopWithIntro(music, introLength, music.Length())
-	musicPlayer, err := audio.NewPlayer(audio.NewContext(sampleRate), musicLoop)
+	musicPlayer, err := audio.NewPlayer(game.audio, musicLoop)
 	if err != nil {
 		log.Fatalf("error making music player: %v\n", err)
 	}
 	musicPlayer.Play()
 
+	// Sound effects
+	game.chirpPlayer = game.audio.NewPlayerFromBytes(
+		loadWavFile("assets/chirp.wav", sampleRate),
+	)
+
 	game.Loading = false
 }
 
@@ -175,8 +266,45 @@ func (g *Game) Update() error {
 		return nil
 	}
 
+	// Hit-stop freezes everything for a moment on big impacts
+	if g.view.Shake.Update() {
+		g.view.Apply(g.cam)
+		return nil
+	}
+
+	g.particles.Update()
+	g.updateParallax()
+	// Emitters, sprite animations, wind streaks, etc. keep going even while
+	// the cricket is dying
+	g.entities.Update(g)
+
+	// Nothing else happens while the cricket is dying
+	if g.dying != nil {
+		g.updateDying()
+		g.view.Apply(g.cam)
+		return nil
+	}
+
+	// The cricket waits in the exit while it bursts into pollen
+	if g.exiting != nil {
+		g.updateExiting()
+		g.view.Apply(g.cam)
+		return nil
+	}
+
+	// The level intro plays before the cricket can move
+	if g.intro != nil {
+		g.updateIntro()
+		return nil
+	}
+
+	g.ticks++
+	g.addBlackness(g.blackCurve.Ticked(g.ticks))
+	g.updateChirp()
+
 	// Skip to next level
 	if DebugMode && inpututil.IsKeyJustPressed(ebiten.KeyN) {
+		g.attempt++
 		g.Reset(g.Level + 1)
 		g.win = true
 	}
@@ -224,10 +352,14 @@ func (g *Game) Update() error {
 			JumpPress = JumpPressCancel
 			return
 		}
-		touchX, _ := ebiten.TouchPosition(g.touchIDs[0])
+		touchX, touchY := ebiten.TouchPosition(g.touchIDs[0])
 		if touchX == 0 {
 			return
 		}
+		// The top of the screen is for chirping
+		if touchY < g.Height/5 {
+			return
+		}
 		if touchX < g.Width/2 {
 			JumpPress = JumpPressLeft
 			return
@@ -257,28 +389,23 @@ func (g *Game) Update() error {
 				return
 			}
 			if g.Cricket.PrimeDuration > 0 {
-				g.Cricket.PrimeDuration /= VelocityDenominator
-				if g.Cricket.PrimeDuration > MaxPrime {
-					g.Cricket.PrimeDuration = MaxPrime
+				g.Cricket.PrimeDuration /= g.rules.VelocityDenominator
+				if g.Cricket.PrimeDuration > g.rules.MaxPrime {
+					g.Cricket.PrimeDuration = g.rules.MaxPrime
 				}
-				if g.Cricket.PrimeDuration < MinPrime {
-					g.Cricket.PrimeDuration = MinPrime
+				if g.Cricket.PrimeDuration < g.rules.MinPrime {
+					g.Cricket.PrimeDuration = g.rules.MinPrime
 				}
 				g.Cricket.Jumping = true
 				g.Cricket.State = Jumping
+				g.Cricket.Peak = g.Cricket.Position.Y
 				debugNumberOfJumps++
 				debugLastJumpStrength = g.Cricket.PrimeDuration
 				g.Cricket.Velocity.Y = g.Cricket.PrimeDuration
 				g.Cricket.Velocity.X =
-					VelocityXMultiplier * g.Cricket.PrimeDuration * g.Cricket.Direction
+					g.rules.VelocityXMultiplier * g.Cricket.PrimeDuration * g.Cricket.Direction
 				g.Cricket.PrimeDuration = 0
-				g.blackFactor = debugNumberOfJumps / BlacknessFactor
-				for i := 0; i < 2^g.blackFactor; i++ {
-					g.blackness[image.Pt(
-						rand.Intn(g.Width/16),
-						rand.Intn(g.Height/16),
-					)] = true
-				}
+				g.addBlackness(g.blackCurve.Jumped(debugNumberOfJumps))
 			}
 		}
 	}()
@@ -286,9 +413,13 @@ func (g *Game) Update() error {
 	g.Wait = (g.Wait + 1) % g.WaitTime
 
 	// Move the cricket
+	sweep := 0
 	if g.Wait%g.WaitTime == 0 {
-		if g.Cricket.Velocity.Y > -5 {
-			g.Cricket.Velocity.Y--
+		// Gravity builds up until it's strong enough to slow the cricket
+		for g.fall += g.rules.Gravity; g.fall >= 100; g.fall -= 100 {
+			if g.Cricket.Velocity.Y > -5 {
+				g.Cricket.Velocity.Y--
+			}
 		}
 		if g.Cricket.Velocity.X < 0 {
 			g.Cricket.Velocity.X++
@@ -296,6 +427,9 @@ func (g *Game) Update() error {
 		if g.Cricket.Velocity.X > 0 {
 			g.Cricket.Velocity.X--
 		}
+		// Wind and currents push after friction so even gentle ones move
+		// the cricket
+		sweep = g.pushZones()
 	}
 
 	// Animation ...these magic numbers refer to frames in cricket.png
@@ -322,40 +456,55 @@ func (g *Game) Update() error {
 
 	// Save pos for after collision
 	oldPos := g.Cricket.Position
+	wasJumping := g.Cricket.Jumping
+	oldVelocity := g.Cricket.Velocity
 
 	// Jump arc
 	if g.Cricket.Jumping {
 		g.Cricket.Position.X = g.Cricket.Position.X - g.Cricket.Velocity.X
-		// keep within the map
-		if g.Cricket.Position.X < 0 {
+		g.Cricket.Position.Y = g.Cricket.Position.Y - g.Cricket.Velocity.Y
+		if g.Cricket.Position.Y < g.Cricket.Peak {
+			g.Cricket.Peak = g.Cricket.Position.Y
+		}
+		// carry on into the next level over in the world
+		oldPos = oldPos.Add(g.crossing())
+		// keep within the map, unless there's a level next to it to go into
+		level := g.Levels[g.Level]
+		hitbox := g.Cricket.Hitbox()
+		middle := hitbox.Min.Y + hitbox.Dy()/2
+		if g.Cricket.Position.X < 0 && g.neighbourAt(image.Pt(-1, middle)) < 0 {
 			g.Cricket.Position.X = 0
 		}
-		if g.Cricket.Position.X+g.Cricket.Hitbox().Dx() > g.LDTKProject.Levels[g.Level].Width {
-			g.Cricket.Position.X = g.Width - g.Cricket.Width
+		if g.Cricket.Position.X+hitbox.Dx() > level.Width && g.neighbourAt(image.Pt(level.Width, middle)) < 0 {
+			g.Cricket.Position.X = level.Width - hitbox.Dx()
 		}
-		g.Cricket.Position.Y = g.Cricket.Position.Y - g.Cricket.Velocity.Y
+	} else if sweep != 0 {
+		// Currents carry the cricket along the ground
+		g.sweep(sweep)
+	}
+
+	// Fell off the bottom of the map
+	if g.Cricket.Position.Y > g.Levels[g.Level].Height {
+		g.Kill(DeathOutOfBounds)
+		return nil
+	}
+
+	// Collect seeds, reach checkpoints, pick up keys, etc. and stop if the
+	// cricket reached an exit
+	entities := g.entities
+	entities.Touch(g, g.Cricket.Hitbox())
+	if g.entities != entities || g.win || g.exiting != nil {
+		return nil
 	}
 
 	// Collision response
 	if v := Collides(g); v != nil {
 		for _, w := range TilesWater {
-			if v.ID == w {
-				log.Println("Hit water, restarting level")
-				g.Reset(g.Level)
+			if v.ID == w && g.rules.WaterDeadly {
+				g.Kill(DeathWater)
 				return nil
 			}
 		}
-		tiles := g.LDTKProject.Levels[g.Level].Layers[LayerTile]
-		exit := g.EntityByIdentifier("Exit")
-		exitbox := image.Rect(
-			exit.Position[0], exit.Position[1],
-			exit.Position[0]+tiles.GridSize, exit.Position[1]+tiles.GridSize,
-		)
-		if exitbox.Overlaps(g.Cricket.Hitbox()) {
-			log.Println("Found the exit, you win!")
-			g.win = true
-			return nil
-		}
 		if g.Cricket.Velocity.Y > 0 {
 			g.Cricket.Velocity.Y *= -1 // Invert on hit
 		} else {
@@ -374,6 +523,30 @@ func (g *Game) Update() error {
 			g.Cricket.Position = oldPos
 		}
 	}
+	// Doors are solid until they're opened
+	if d := CollidesDoor(g); d != nil && !g.unlockDoor(d) {
+		if g.Cricket.Velocity.Y > 0 {
+			g.Cricket.Velocity.Y *= -1 // Invert on hit
+		} else {
+			g.Cricket.Jumping = false
+			g.Cricket.State = Idle
+		}
+		g.Cricket.Position = oldPos
+	}
+
+	// Kick up some dust and shake the screen on landing
+	if wasJumping && !g.Cricket.Jumping {
+		feet := g.Cricket.Hitbox()
+		g.particles.Emit(&ParticleDust, 8,
+			float64(feet.Min.X+feet.Dx()/2), float64(feet.Max.Y),
+		)
+		g.landing(g.Cricket.Position.Y - g.Cricket.Peak)
+	}
+	// Bumping into a ceiling shakes it too
+	if oldVelocity.Y > 0 && g.Cricket.Velocity.Y < 0 {
+		g.bump(oldVelocity.Y)
+	}
+
 	// Landing state
 	if g.Cricket.Jumping && g.Cricket.Velocity.Y <= 0 {
 		g.Cricket.State = Landing
@@ -388,29 +561,26 @@ func (g *Game) Update() error {
 	}
 
 	// Position camera
-	camX, camY := 0, 0
-	// Clamp the Camera to the Map dimensions
-	// Surely there is an easier way to do this with maths... ಠ_ಠ
-	func() {
-		level := g.LDTKProject.Levels[g.Level]
-		cpos := g.Cricket.Position
-		cpos.X, cpos.Y = cpos.X+g.Cricket.Width/2, cpos.Y+g.Cricket.Image.Bounds().Dy()
-		if cpos.X-g.Width/2 < 0 {
-			camX = g.Width / 2
-		} else if cpos.X+g.Width/2 > level.Width {
-			camX = level.Width - g.Width/2
-		} else {
-			camX = cpos.X
-		}
-		if cpos.Y-g.Height/2 < 0 {
-			camY = g.Height / 2
-		} else if cpos.Y+g.Height/2 > level.Height {
-			camY = level.Height - g.Height/2
-		} else {
-			camY = cpos.Y
-		}
-	}()
-	g.cam.SetPosition(float64(camX), float64(camY))
+	level := g.Levels[g.Level]
+	if g.overviewHeld() {
+		g.view.Overview(
+			image.Pt(g.Width, g.Height),
+			image.Rect(0, 0, level.Width, level.Height),
+		)
+		g.view.Apply(g.cam)
+		return nil
+	}
+	g.view.Follow(
+		g.Cricket.Position.Add(image.Pt(
+			g.Cricket.Width/2, g.Cricket.Image.Bounds().Dy(),
+		)),
+		g.Cricket.Direction,
+		g.primed(),
+		image.Pt(g.Width, g.Height),
+		g.worldBounds(),
+		g.cameraZone(),
+	)
+	g.view.Apply(g.cam)
 
 	return nil
 }
@@ -423,32 +593,80 @@ func (g *Game) Draw(screen *ebiten.Image) {
 	}
 
 	if g.win {
-		w := WinScreen(debugNumberOfJumps)
+		level := g.Levels[g.Level].Identifier
+		w := WinScreen{
+			Jumps:      debugNumberOfJumps,
+			Seeds:      g.seeds.Collected(),
+			TotalSeeds: len(g.seeds),
+			BestSeeds:  g.save.Seeds[level],
+			Deaths:     g.DeathsInLevel(),
+		}
 		w.Draw(g, screen)
 		return
 	}
 
-	g.cam.Surface.Clear()
-	g.cam.Surface.DrawImage(g.bg, g.cam.GetTranslation(0, 0))
+	g.drawParallax()
+	g.TileRenderer.Draw(g.cam)
+	g.entities.Draw(g)
+
+	if g.dying != nil {
+		g.drawDying()
+	} else {
+		frameSize := g.Cricket.Width
+		g.Cricket.Op.GeoM.Concat(g.cam.GetTranslation(
+			float64(g.Cricket.Position.X), float64(g.Cricket.Position.Y),
+		).GeoM)
+		g.cam.Surface.DrawImage(g.Cricket.Image.SubImage(image.Rect(
+			g.Cricket.Frame*frameSize, 0, (1+g.Cricket.Frame)*frameSize, frameSize,
+		)).(*ebiten.Image), g.Cricket.Op)
+	}
+
+	g.particles.Draw(g)
 
-	frameSize := g.Cricket.Width
-	g.Cricket.Op.GeoM.Concat(g.cam.GetTranslation(
-		float64(g.Cricket.Position.X), float64(g.Cricket.Position.Y),
-	).GeoM)
-	g.cam.Surface.DrawImage(g.Cricket.Image.SubImage(image.Rect(
-		g.Cricket.Frame*frameSize, 0, (1+g.Cricket.Frame)*frameSize, frameSize,
-	)).(*ebiten.Image), g.Cricket.Op)
+	if g.rules.BlacknessSpace == BlacknessWorld {
+		g.drawBlackness(g.cam.Surface)
+		g.drawChirp(g.cam.Surface)
+	}
 
-	g.cam.Blit(screen)
+	g.view.Blit(g.cam, screen)
 
-	for b := range g.blackness {
-		ebitenutil.DrawRect(screen,
-			float64(b.X*16), float64(b.Y*16),
-			16, 16,
-			color.Black,
+	if g.rules.BlacknessSpace != BlacknessWorld {
+		g.drawBlackness(screen)
+		g.drawChirp(screen)
+	}
+
+	// Seed counter, only when there's something to collect
+	if len(g.seeds) > 0 {
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Scale(2, 2)
+		op.GeoM.Translate(float64(g.Width-96), 8)
+		screen.DrawImage(g.seedImg, op)
+		text.Draw(screen,
+			fmt.Sprintf("%d/%d", g.seeds.Collected(), len(g.seeds)),
+			g.fontSmall, g.Width-72, 24, color.White,
+		)
+	}
+
+	// Chirps left
+	if g.chirpsOn() {
+		text.Draw(screen,
+			fmt.Sprintf("%d CHIRPS", g.chirpsLeft),
+			g.fontSmall, g.Width-144, 48, color.White,
 		)
 	}
 
+	if g.dying != nil {
+		g.drawDeathFade(screen)
+	}
+
+	if g.minimapShown() {
+		g.drawMinimap(screen)
+	}
+
+	if g.intro != nil {
+		g.drawIntro(screen)
+	}
+
 	if DebugMode {
 		debug(screen, g)
 	}
@@ -462,19 +680,123 @@ func (g *Game) Layout(outsideWidth int, outsideHeight int) (screenWidth int, scr
 // Reset resets the game level and cricket states to defaults for a provided
 // game level
 func (g *Game) Reset(level int) {
-	g.Level = (level) % len(g.LDTKProject.Levels)
+	changed := g.switchLevel(level)
+	if changed {
+		g.particles.Clear()
+		g.startParallax()
+	}
+	g.resetRun()
+	if changed {
+		g.startIntro()
+	}
+}
+
+// switchLevel makes the given level the current one, with its tiles, rules
+// and entities, but leaves the cricket and the progress of the run alone; it
+// returns whether it's a different level to before
+func (g *Game) switchLevel(level int) bool {
+	changed := g.Level != level%len(g.Levels)
+	g.Level = (level) % len(g.Levels)
 	log.Println("Switching to Level", g.Level)
-	g.Cricket = NewCricket(g.EntityByIdentifier("Cricket").Position)
+	g.loadLevel(g.Level)
+	if changed {
+		g.renderLevel()
+	}
+	g.rules = LevelRules(g.Levels[g.Level])
+	g.loadLevelEntities()
+	return changed
+}
+
+// resetRun starts the run through the current level over, with a new cricket
+// at the spawn point and no blackness, jumps or checkpoint
+func (g *Game) resetRun() {
+	g.blackCurve = NewBlacknessCurve(g.rules.BlacknessMode, g.rules.BlacknessFactor)
+	g.fall = 0
+	g.ticks = 0
+	g.Cricket = NewCricket(g.SpawnPoint(""))
+	g.respawn = nil
+	g.exiting = nil
 	g.blackness = make(map[image.Point]bool)
+	g.revealed = make(map[image.Point]int)
+	g.chirp = nil
+	g.chirpsLeft = g.rules.ChirpUses
+	g.view.Snap()
+	g.view.Shake.Reset()
 	debugNumberOfJumps = 0
 }
 
-// EntityByIdentifier is a convenience function for the same thing in ldtkgo but
-// defaulting to checking the Entities layer of the current level
-func (g *Game) EntityByIdentifier(identifier string) *ldtkgo.Entity {
-	return g.LDTKProject.Levels[g.Level].
-		Layers[LayerEntities].
-		EntityByIdentifier(identifier)
+// loadLevelEntities makes all the entities of the current level, and keeps
+// lists of the kinds the rest of the game needs to find
+func (g *Game) loadLevelEntities() {
+	g.entities = g.loadEntities()
+	g.seeds = EntitiesOf[*Seed](g.entities)
+	g.doors = EntitiesOf[*Door](g.entities)
+	g.keys = EntitiesOf[*Key](g.entities)
+	g.exits = EntitiesOf[*Exit](g.entities)
+	g.cameraZones = EntitiesOf[*CameraZone](g.entities)
+	g.zones = EntitiesOf[*Zone](g.entities)
+}
+
+// renderLevel renders the tiles of the current level in chunks that are drawn
+// over the parallax background
+func (g *Game) renderLevel() {
+	level := g.Levels[g.Level]
+
+	// Render map
+	g.TileRenderer.Render(level)
+	g.renderMinimap()
+	// The neighbours are rendered after the minimap so they aren't on it
+	for _, i := range g.neighbours() {
+		g.loadLevel(i)
+		g.TileRenderer.RenderAt(g.Levels[i], g.levelOffset(i))
+	}
+}
+
+// saveSeeds records the seeds collected in the current level in the save file
+// if it's a new best for that level
+func (g *Game) saveSeeds() {
+	level := g.Levels[g.Level].Identifier
+	if !g.save.RecordSeeds(level, g.seeds.Collected()) {
+		return
+	}
+	if err := g.save.Write(SaveFile); err != nil {
+		log.Println("error saving progress:", err)
+	}
+}
+
+// EntityByIdentifier is a convenience function for the same thing on a layer
+// but checking all the entity layers of the current level
+func (g *Game) EntityByIdentifier(identifier string) *MapEntity {
+	for _, layer := range g.entityLayers() {
+		if e := layer.EntityByIdentifier(identifier); e != nil {
+			return e
+		}
+	}
+	return nil
+}
+
+// LevelBool returns the value of a boolean field set on the current level in
+// LDtk, or the given default if the level doesn't have that field
+func (g *Game) LevelBool(identifier string, def bool) bool {
+	p := g.Levels[g.Level].PropertyByIdentifier(identifier)
+	if p == nil || p.IsNull() {
+		return def
+	}
+	return p.AsBool()
+}
+
+// EntitiesByIdentifier is like EntityByIdentifier but it returns all entities
+// in the entity layers of the current level with the given identifier
+func (g *Game) EntitiesByIdentifier(identifier string) []*MapEntity {
+	var entities []*MapEntity
+	for _, layer := range g.entityLayers() {
+		for _, e := range layer.Entities {
+			if e.Identifier == identifier {
+				entities = append(entities, e)
+			}
+		}
+	}
+	return entities
 }
 
 // An Object is something that can be seen and positioned in the game
@@ -518,6 +840,7 @@ type Cricket struct {
 	Frame         int
 	Width         int
 	State         CricketState
+	Peak          int // Highest Y position since it last left the ground
 }
 
 // NewCricket returns a new Cricket object at the given position
@@ -529,6 +852,7 @@ func NewCricket(cricketPos []int) *Cricket {
 		Jumping:   true,
 		Direction: 1,
 		Position:  image.Pt(cricketPos[0], cricketPos[1]),
+		Peak:      cricketPos[1],
 		Frame:     1,
 		Width:     37,
 	}
@@ -543,6 +867,16 @@ func (c *Cricket) Hitbox() image.Rectangle {
 	))
 }
 
+// primed returns how far the cricket is towards priming a full strength jump,
+// from 0 to 1
+func (g *Game) primed() float64 {
+	full := g.rules.MaxPrime * g.rules.VelocityDenominator
+	if full < 1 {
+		return 0
+	}
+	return math.Min(float64(g.Cricket.PrimeDuration)/float64(full), 1)
+}
+
 // ApplyConfigs overrides default values with a config file if available
 func ApplyConfigs() {
 	log.Println("Looking for INI file...")
@@ -554,15 +888,28 @@ func ApplyConfigs() {
 		MaxPrime, _ = cfg.Section("").Key("MaxPrime").Int()
 		MinPrime, _ = cfg.Section("").Key("MinPrime").Int()
 		DebugMode, _ = cfg.Section("").Key("DebugMode").Bool()
+		Gravity = cfg.Section("").Key("Gravity").MustInt(Gravity)
+		BlacknessFactor = cfg.Section("").Key("BlacknessFactor").MustInt(BlacknessFactor)
+		BlacknessMode = cfg.Section("").Key("BlacknessMode").MustString(BlacknessMode)
+		BlacknessSpace = cfg.Section("").Key("BlacknessSpace").MustString(BlacknessSpace)
+		ChirpUses = cfg.Section("").Key("ChirpUses").MustInt(ChirpUses)
+		CameraLerp = cfg.Section("").Key("CameraLerp").MustFloat64(CameraLerp)
+		CameraDeadzoneX = cfg.Section("").Key("CameraDeadzoneX").MustInt(CameraDeadzoneX)
+		CameraDeadzoneY = cfg.Section("").Key("CameraDeadzoneY").MustInt(CameraDeadzoneY)
+		CameraLookAhead = cfg.Section("").Key("CameraLookAhead").MustInt(CameraLookAhead)
+		CameraPrimeZoom = cfg.Section("").Key("CameraPrimeZoom").MustFloat64(CameraPrimeZoom)
+		ShowMinimap = cfg.Section("").Key("ShowMinimap").MustBool(ShowMinimap)
+		HardMode = cfg.Section("").Key("HardMode").MustBool(HardMode)
+		ScreenShake = cfg.Section("").Key("ScreenShake").MustFloat64(ScreenShake)
+		HitStop = cfg.Section("").Key("HitStop").MustBool(HitStop)
+		if cfg.Section("").HasKey("MapFiles") {
+			MapFiles = cfg.Section("").Key("MapFiles").Strings(",")
+		}
+		if cfg.Section("").HasKey("CollisionLayers") {
+			CollisionLayers = cfg.Section("").Key("CollisionLayers").Strings(",")
+		}
+		if cfg.Section("").HasKey("EntityLayers") {
+			EntityLayers = cfg.Section("").Key("EntityLayers").Strings(",")
+		}
 	}
 }
-
-// Blackness is a map to store where black squares should appear on the camera
-type Blackness map[image.Point]bool
-
-// Has is a convenience function to check if a point on the camera is already
-// black, it can be used in-line because it only returns one value
-func (b Blackness) Has(v image.Point) bool {
-	_, ok := b[v]
-	return ok
-}
@@ -0,0 +1,81 @@
+package cr1ckt
+
+import (
+	"testing"
+
+	camera "github.com/melonfunction/ebiten-camera"
+)
+
+// testLevel is a Tiled map with a floor along the bottom, a checkpoint, a
+// seed and a key, and a door that a switch and the key both have to open
+const testLevel = `<?xml version="1.0" encoding="UTF-8"?>
+<map orientation="orthogonal" width="20" height="10" tilewidth="16" tileheight="16">
+ <tileset firstgid="1" tilewidth="16" tileheight="16" tilecount="64" columns="8">
+  <image source="tileset.png" width="128" height="128"/>
+ </tileset>
+ <layer id="1" name="Tiles" width="20" height="10">
+  <data encoding="csv">
+0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,
+0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,
+0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,
+0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,
+0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,
+0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,
+0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,
+0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,
+0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,
+1,1,1,1,1,1,1,1,1,1,1,1,1,1,1,1,1,1,1,1
+</data>
+ </layer>
+ <objectgroup id="2" name="Entities">
+  <object id="1" type="Cricket" x="16" y="96" width="16" height="16"/>
+  <object id="2" type="Checkpoint" x="96" y="112" width="16" height="32"/>
+  <object id="3" type="Seed" x="160" y="128" width="8" height="8"/>
+  <object id="4" type="Key" x="192" y="128" width="16" height="16"/>
+  <object id="5" type="Door" x="240" y="96" width="16" height="48">
+   <properties>
+    <property name="NeedsKey" type="bool" value="true"/>
+   </properties>
+  </object>
+  <object id="6" type="Switch" x="208" y="140" width="16" height="4">
+   <properties>
+    <property name="Doors" type="object" value="5"/>
+   </properties>
+  </object>
+ </objectgroup>
+</map>
+`
+
+// newTestGame returns a game playing the given Tiled map, set up like NewGame
+// but without the sounds, fonts and save file
+func newTestGame(t *testing.T, tmx string) *Game {
+	t.Helper()
+	maps, err := ReadTiled("assets/packs/Test.tmx", []byte(tmx), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := &Game{
+		Width:        640,
+		Height:       480,
+		WaitTime:     10,
+		TileRenderer: NewTileRenderer(testLoader{}),
+		Maps:         maps,
+		Levels:       maps.Levels(),
+		seedImg:      loadImage("assets/seed.png"),
+		flagImg:      loadImage("assets/checkpoint.png"),
+		keyImg:       loadImage("assets/key.png"),
+		save:         &Save{Seeds: make(map[string]int)},
+		cam:          camera.NewCamera(640, 480, 0, 0, 0, 1),
+		view:         NewCameraController(),
+	}
+	g.Reset(0)
+	return g
+}
+
+// die kills the cricket and plays out the whole death sequence
+func (g *Game) die(cause DeathCause) {
+	g.Kill(cause)
+	for g.dying != nil {
+		g.updateDying()
+	}
+}
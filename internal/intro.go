@@ -0,0 +1,94 @@
+// Copyright 2021 Siôn le Roux.  All rights reserved.
+// Use of this source code is subject to an MIT-style
+// licence which can be found in the LICENSE file.
+
+package cr1ckt
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/font"
+)
+
+// Lengths of each part of the level intro, in ticks
+const (
+	introHoldTicks = 60  // looking at the exit
+	introPanTicks  = 120 // panning over to the cricket
+)
+
+// Intro is the camera pan from the exit to the cricket that shows where you
+// have to go at the start of a level
+type Intro struct {
+	From image.Point
+	Name string
+	Tick int
+}
+
+// startIntro starts the intro for the current level, unless the level has its
+// Intro field unticked or has no exit to show
+func (g *Game) startIntro() {
+	g.intro = nil
+	if !g.LevelBool("Intro", true) || len(g.exits) == 0 {
+		return
+	}
+	level := g.Levels[g.Level]
+	name := strings.ReplaceAll(level.Identifier, "_", " ")
+	if p := level.PropertyByIdentifier("Name"); p != nil && !p.IsNull() {
+		name = p.AsString()
+	}
+	exit := g.exits[0].Rect
+	g.intro = &Intro{
+		From: exit.Min.Add(exit.Size().Div(2)),
+		Name: name,
+	}
+}
+
+// introSkipped returns whether the player pressed anything to skip the intro
+func (g *Game) introSkipped() bool {
+	for _, k := range []ebiten.Key{
+		ebiten.KeyLeft, ebiten.KeyRight, ebiten.KeyA, ebiten.KeyD,
+		ebiten.KeySpace, ebiten.KeyEnter, ebiten.KeyC, ebiten.KeyUp, ebiten.KeyW,
+	} {
+		if inpututil.IsKeyJustPressed(k) {
+			return true
+		}
+	}
+	return len(inpututil.AppendJustPressedTouchIDs(nil)) > 0
+}
+
+// updateIntro moves the camera along the intro pan and ends the intro when
+// it's finished or skipped
+func (g *Game) updateIntro() {
+	g.intro.Tick++
+	if g.intro.Tick >= introHoldTicks+introPanTicks || g.introSkipped() {
+		g.intro = nil
+		return
+	}
+	t := math.Max(float64(g.intro.Tick-introHoldTicks), 0) / introPanTicks
+	t = t * t * (3 - 2*t) // ease in and out
+	from, to := g.intro.From, g.Cricket.Position.Add(image.Pt(
+		g.Cricket.Width/2, g.Cricket.Image.Bounds().Dy(),
+	))
+	level := g.Levels[g.Level]
+	g.view.LookAt(
+		float64(from.X)+float64(to.X-from.X)*t,
+		float64(from.Y)+float64(to.Y-from.Y)*t,
+		image.Pt(g.Width, g.Height),
+		image.Rect(0, 0, level.Width, level.Height),
+	)
+	g.view.Apply(g.cam)
+}
+
+// drawIntro draws the level name over the top of the screen during the intro
+func (g *Game) drawIntro(screen *ebiten.Image) {
+	bounds, _ := font.BoundString(g.fontBig, g.intro.Name)
+	w := (bounds.Max.X - bounds.Min.X).Ceil()
+	h := (bounds.Max.Y - bounds.Min.Y).Ceil()
+	text.Draw(screen, g.intro.Name, g.fontBig, g.Width/2-w/2, h*3, color.White)
+}
@@ -0,0 +1,98 @@
+// Copyright 2021 Siôn le Roux.  All rights reserved.
+// Use of this source code is subject to an MIT-style
+// licence which can be found in the LICENSE file.
+
+package cr1ckt
+
+import (
+	"log"
+	"path"
+)
+
+// LayersMatching returns the layers of a level whose identifiers match the
+// patterns, in the order of the patterns and then the order of the layers
+func LayersMatching(level *MapLevel, patterns []string) []*MapLayer {
+	var layers []*MapLayer
+	seen := make(map[*MapLayer]bool)
+	for _, pattern := range patterns {
+		for _, layer := range level.Layers {
+			if ok, _ := path.Match(pattern, layer.Identifier); ok && !seen[layer] {
+				seen[layer] = true
+				layers = append(layers, layer)
+			}
+		}
+	}
+	return layers
+}
+
+// checkLayers makes sure every level of the maps has at least one
+// collision layer and one entity layer of the right types, otherwise the game
+// can't be played so it stops with an error saying what's missing; levels in
+// their own files are checked when they're loaded
+func checkLayers(levels []*MapLevel) {
+	for _, level := range levels {
+		if level.Loaded {
+			checkLevel(level)
+		}
+	}
+}
+
+// checkLevel makes sure a level has the layers the game needs like
+// checkLayers
+func checkLevel(level *MapLevel) {
+	var names []string
+	for _, layer := range level.Layers {
+		names = append(names, layer.Identifier+" ("+string(layer.Type)+")")
+	}
+
+	var collision int
+	for _, layer := range LayersMatching(level, CollisionLayers) {
+		if layer.Type != MapLayerEntities {
+			collision++
+		}
+	}
+	if collision == 0 {
+		log.Fatalf("level %s has no tile layer matching %v to collide with, its layers are %v\n",
+			level.Identifier, CollisionLayers, names)
+	}
+
+	var entities int
+	for _, layer := range LayersMatching(level, EntityLayers) {
+		if layer.Type == MapLayerEntities {
+			entities++
+		}
+	}
+	if entities == 0 {
+		log.Fatalf("level %s has no entity layer matching %v, its layers are %v\n",
+			level.Identifier, EntityLayers, names)
+	}
+}
+
+// collisionLayers returns the layers of the current level the cricket
+// collides with
+func (g *Game) collisionLayers() []*MapLayer {
+	var layers []*MapLayer
+	for _, layer := range LayersMatching(g.Levels[g.Level], CollisionLayers) {
+		if layer.Type != MapLayerEntities {
+			layers = append(layers, layer)
+		}
+	}
+	return layers
+}
+
+// entityLayers returns the layers of the current level entities are loaded
+// from, in the order they are in LDtk
+func (g *Game) entityLayers() []*MapLayer {
+	level := g.Levels[g.Level]
+	matched := make(map[*MapLayer]bool)
+	for _, layer := range LayersMatching(level, EntityLayers) {
+		matched[layer] = true
+	}
+	var layers []*MapLayer
+	for _, layer := range level.Layers {
+		if matched[layer] && layer.Type == MapLayerEntities {
+			layers = append(layers, layer)
+		}
+	}
+	return layers
+}
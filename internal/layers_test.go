@@ -0,0 +1,35 @@
+package cr1ckt
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestLayersMatching(t *testing.T) {
+	level := &MapLevel{Layers: []*MapLayer{
+		{Identifier: "Entities"},
+		{Identifier: "IntGrid"},
+		{Identifier: "Tiles"},
+		{Identifier: "Tiles_front"},
+		{Identifier: "Background"},
+	}}
+	cases := []struct {
+		patterns []string
+		want     []string
+		comment  string
+	}{
+		{[]string{"Tiles*", "IntGrid*"}, []string{"Tiles", "Tiles_front", "IntGrid"}, "default collision layers"},
+		{[]string{"Entities*"}, []string{"Entities"}, "default entity layers"},
+		{[]string{"Tiles", "Tiles*"}, []string{"Tiles", "Tiles_front"}, "no layer twice"},
+		{[]string{"Walls"}, nil, "missing layer"},
+	}
+	for _, c := range cases {
+		var got []string
+		for _, l := range LayersMatching(level, c.patterns) {
+			got = append(got, l.Identifier)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(c.want) {
+			t.Errorf("Layers matching %s are %v, want %v", c.comment, got, c.want)
+		}
+	}
+}
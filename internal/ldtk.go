@@ -0,0 +1,346 @@
+// Copyright 2021 Siôn le Roux.  All rights reserved.
+// Use of this source code is subject to an MIT-style
+// licence which can be found in the LICENSE file.
+
+package cr1ckt
+
+import (
+	"encoding/json"
+	"image"
+	"path"
+	"path/filepath"
+
+	"github.com/solarlune/ldtkgo"
+)
+
+// LDTKSource is a MapSource for an LDtk project file, it reads the project
+// with ldtkgo along with the data ldtkgo skips
+type LDTKSource struct {
+	Name     string                            // Of the project file, in the embedded FS
+	ReadFile func(name string) ([]byte, error) // Reads levels saved in their own files
+	levels   []*MapLevel                       // In the order they're in the project
+	external map[*MapLevel]string              // Levels that are in their own files
+	defs     *ldtkDefs                         // Of the whole project
+	tilesets map[*ldtkgo.Tileset]*MapTileset   // The same tileset for every level
+}
+
+// ldtkDefs are the definitions from the project file that its levels are
+// read with, kept for the levels that are in their own files and read later
+type ldtkDefs struct {
+	Raw    json.RawMessage `json:"-"`
+	Layers []struct {
+		UID             int     `json:"uid"`
+		ParallaxFactorX float64 `json:"parallaxFactorX"`
+		ParallaxFactorY float64 `json:"parallaxFactorY"`
+		ParallaxScaling bool    `json:"parallaxScaling"`
+	} `json:"layers"`
+	Tilesets map[int]*MapTileset `json:"-"` // By UID
+}
+
+// ldtkLevel is the part of an LDtk level that ldtkgo skips
+type ldtkLevel struct {
+	ExternalRelPath string `json:"externalRelPath"` // Set when the level is in its own file
+	LayerInstances  []struct {
+		LayerDefUID     int      `json:"layerDefUid"`
+		Opacity         *float64 `json:"__opacity"`
+		EntityInstances []struct {
+			Iid  string    `json:"iid"` // Needs LDtk 1.0 or newer
+			Tile *ldtkTile `json:"__tile"`
+		} `json:"entityInstances"`
+	} `json:"layerInstances"`
+}
+
+// ldtkTile is a tile an LDtk entity is drawn with or has in a tile field
+type ldtkTile struct {
+	TilesetUID int   `json:"tilesetUid"`
+	SrcRect    []int `json:"srcRect"` // x, y, w, h before LDtk 1.0
+	X          int   `json:"x"`
+	Y          int   `json:"y"`
+	W          int   `json:"w"`
+	H          int   `json:"h"`
+}
+
+// src returns the part of the tileset image the tile is
+func (t *ldtkTile) src() image.Rectangle {
+	if len(t.SrcRect) == 4 {
+		return image.Rect(
+			t.SrcRect[0], t.SrcRect[1],
+			t.SrcRect[0]+t.SrcRect[2], t.SrcRect[1]+t.SrcRect[3],
+		)
+	}
+	return image.Rect(t.X, t.Y, t.X+t.W, t.Y+t.H)
+}
+
+// ReadLDTK reads an LDtk project file, levels saved in their own files are
+// read with readFile when they're loaded
+func ReadLDTK(name string, data []byte, readFile func(string) ([]byte, error)) (*LDTKSource, error) {
+	// ldtkgo only looks for levels at the top, not in worlds
+	levels, err := flattenWorlds(data)
+	if err != nil {
+		return nil, err
+	}
+	project, err := ldtkgo.Read(levels)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw struct {
+		Defs        json.RawMessage `json:"defs"`
+		WorldLayout string          `json:"worldLayout"`
+		Levels      []ldtkLevel     `json:"levels"`
+		Worlds      []struct {
+			Identifier  string      `json:"identifier"`
+			WorldLayout string      `json:"worldLayout"`
+			Levels      []ldtkLevel `json:"levels"`
+		} `json:"worlds"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	s := &LDTKSource{
+		Name:     name,
+		ReadFile: readFile,
+		external: make(map[*MapLevel]string),
+		defs:     &ldtkDefs{Raw: raw.Defs, Tilesets: make(map[int]*MapTileset)},
+		tilesets: make(map[*ldtkgo.Tileset]*MapTileset),
+	}
+	if err := json.Unmarshal(raw.Defs, s.defs); err != nil {
+		return nil, err
+	}
+	// Tileset paths are relative to the project file, make them relative to
+	// the assets folder
+	dir := assetsPath(path.Dir(name))
+	for _, ts := range project.Tilesets {
+		mts := &MapTileset{Path: path.Join(dir, filepath.ToSlash(ts.Path))}
+		s.defs.Tilesets[ts.ID] = mts
+		s.tilesets[ts] = mts
+	}
+
+	// Levels in worlds come after the top level ones, like flattenWorlds
+	// puts them, and the world is named after the file so worlds in other
+	// projects are separate
+	type world struct{ identifier, layout string }
+	var rawLevels []ldtkLevel
+	var worlds []world
+	for _, l := range raw.Levels {
+		rawLevels = append(rawLevels, l)
+		worlds = append(worlds, world{name, raw.WorldLayout})
+	}
+	for _, w := range raw.Worlds {
+		for _, l := range w.Levels {
+			rawLevels = append(rawLevels, l)
+			worlds = append(worlds, world{name + "#" + w.Identifier, w.WorldLayout})
+		}
+	}
+
+	for i, l := range project.Levels {
+		level := &MapLevel{
+			Identifier: l.Identifier,
+			World:      worlds[i].identifier,
+			Connected:  worlds[i].layout == ldtkgo.WorldLayoutFree || worlds[i].layout == ldtkgo.WorldLayoutGridVania,
+			WorldX:     l.WorldX,
+			WorldY:     l.WorldY,
+			Width:      l.Width,
+			Height:     l.Height,
+			BGColor:    l.BGColor,
+			Properties: s.properties(l.Properties),
+		}
+		s.levels = append(s.levels, level)
+		if rawLevels[i].ExternalRelPath != "" && len(l.Layers) == 0 {
+			s.external[level] = path.Join(path.Dir(name), rawLevels[i].ExternalRelPath)
+			continue
+		}
+		s.readLayers(level, l, rawLevels[i])
+	}
+	return s, nil
+}
+
+// Levels returns the levels in the project
+func (s *LDTKSource) Levels() []*MapLevel {
+	return s.levels
+}
+
+// LoadLevel reads the layers of a level saved in its own file (.ldtkl)
+func (s *LDTKSource) LoadLevel(level *MapLevel) error {
+	name, ok := s.external[level]
+	if !ok {
+		return nil
+	}
+	data, err := s.ReadFile(name)
+	if err != nil {
+		return err
+	}
+
+	// ldtkgo only reads whole projects, so give it one with just this level
+	project, err := json.Marshal(map[string]json.RawMessage{
+		"defs":   s.defs.Raw,
+		"levels": json.RawMessage("[" + string(data) + "]"),
+	})
+	if err != nil {
+		return err
+	}
+	read, err := ldtkgo.Read(project)
+	if err != nil {
+		return err
+	}
+	var raw ldtkLevel
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	// Share the tilesets of the rest of the project
+	for _, ts := range read.Tilesets {
+		s.tilesets[ts] = s.defs.Tilesets[ts.ID]
+	}
+	s.readLayers(level, read.Levels[0], raw)
+	delete(s.external, level)
+	return nil
+}
+
+// readLayers turns the layers of an ldtkgo level into the level's layers
+func (s *LDTKSource) readLayers(level *MapLevel, l *ldtkgo.Level, raw ldtkLevel) {
+	level.Layers = nil
+	for i, layer := range l.Layers {
+		rawLayer := raw.LayerInstances[i]
+		ml := &MapLayer{
+			Identifier: layer.Identifier,
+			Type:       MapLayerTiles,
+			GridSize:   layer.GridSize,
+			OffsetX:    layer.OffsetX,
+			OffsetY:    layer.OffsetY,
+			Visible:    layer.Visible,
+			Opacity:    1,
+		}
+		if rawLayer.Opacity != nil {
+			ml.Opacity = *rawLayer.Opacity
+		}
+		for _, def := range s.defs.Layers {
+			if def.UID == rawLayer.LayerDefUID {
+				ml.ParallaxX = def.ParallaxFactorX
+				ml.ParallaxY = def.ParallaxFactorY
+				ml.ParallaxScaling = def.ParallaxScaling
+			}
+		}
+
+		if layer.Type == ldtkgo.LayerTypeEntity {
+			ml.Type = MapLayerEntities
+		}
+		// IntGrids have autotiles too
+		for _, t := range layer.AllTiles() {
+			ml.Tiles = append(ml.Tiles, &MapTile{
+				ID:       t.ID,
+				Position: t.Position,
+				Src: image.Rect(0, 0, layer.GridSize, layer.GridSize).
+					Add(image.Pt(t.Src[0], t.Src[1])),
+				Tileset: s.tilesets[layer.Tileset],
+				FlipX:   t.FlipX(),
+				FlipY:   t.FlipY(),
+			})
+		}
+		for j, e := range layer.Entities {
+			rawEntity := rawLayer.EntityInstances[j]
+			me := &MapEntity{
+				Identifier: e.Identifier,
+				Iid:        rawEntity.Iid,
+				Position:   e.Position,
+				Width:      e.Width,
+				Height:     e.Height,
+				Tile:       s.tile(rawEntity.Tile),
+				Properties: s.properties(e.Properties),
+			}
+			for _, p := range e.Pivot {
+				me.Pivot = append(me.Pivot, float64(p))
+			}
+			ml.Entities = append(ml.Entities, me)
+		}
+		level.Layers = append(level.Layers, ml)
+	}
+	level.Loaded = true
+}
+
+// tile returns the tile for an LDtk tile, or nil if its tileset is missing
+func (s *LDTKSource) tile(t *ldtkTile) *EntityTile {
+	if t == nil || s.defs.Tilesets[t.TilesetUID] == nil {
+		return nil
+	}
+	return &EntityTile{Tileset: s.defs.Tilesets[t.TilesetUID], Src: t.src()}
+}
+
+// properties turns LDtk fields into properties, with tiles and entity
+// references turned into the game's types
+func (s *LDTKSource) properties(fields []*ldtkgo.Property) Properties {
+	var ps Properties
+	for _, f := range fields {
+		ps = append(ps, &Property{Identifier: f.Identifier, Value: s.value(f.Value)})
+	}
+	return ps
+}
+
+// value turns the value of an LDtk field into the value of a property
+func (s *LDTKSource) value(v interface{}) interface{} {
+	switch v := v.(type) {
+	case []interface{}:
+		values := make([]interface{}, len(v))
+		for i := range v {
+			values[i] = s.value(v[i])
+		}
+		return values
+	case map[string]interface{}:
+		if iid, ok := v["entityIid"].(string); ok {
+			return EntityRef(iid)
+		}
+		if _, ok := v["tilesetUid"]; ok {
+			// Go round through JSON so the tile is read the same as __tile
+			data, err := json.Marshal(v)
+			if err != nil {
+				return nil
+			}
+			t := &ldtkTile{}
+			if err := json.Unmarshal(data, t); err != nil {
+				return nil
+			}
+			if tile := s.tile(t); tile != nil {
+				return tile
+			}
+			return nil
+		}
+	}
+	return v
+}
+
+// flattenWorlds moves the levels of every world in an LDtk project with
+// multiple worlds into the top level list of levels, which is the only place
+// ldtkgo looks for them
+func flattenWorlds(data []byte) ([]byte, error) {
+	var project map[string]json.RawMessage
+	if err := json.Unmarshal(data, &project); err != nil {
+		return nil, err
+	}
+	var worlds []struct {
+		Levels []json.RawMessage `json:"levels"`
+	}
+	if raw, ok := project["worlds"]; ok {
+		if err := json.Unmarshal(raw, &worlds); err != nil {
+			return nil, err
+		}
+	}
+	if len(worlds) == 0 {
+		return data, nil
+	}
+
+	var levels []json.RawMessage
+	if raw, ok := project["levels"]; ok {
+		if err := json.Unmarshal(raw, &levels); err != nil {
+			return nil, err
+		}
+	}
+	for _, w := range worlds {
+		levels = append(levels, w.Levels...)
+	}
+	raw, err := json.Marshal(levels)
+	if err != nil {
+		return nil, err
+	}
+	project["levels"] = raw
+	return json.Marshal(project)
+}
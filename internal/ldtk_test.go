@@ -0,0 +1,113 @@
+package cr1ckt
+
+import (
+	"encoding/json"
+	"io/fs"
+	"reflect"
+	"testing"
+)
+
+func TestFlattenWorlds(t *testing.T) {
+	data := []byte(`{"levels":[{"identifier":"A"}],"worlds":[` +
+		`{"identifier":"W1","levels":[{"identifier":"B"}]},` +
+		`{"identifier":"W2","levels":[{"identifier":"C"},{"identifier":"D"}]}]}`)
+	flat, err := flattenWorlds(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var project struct {
+		Levels []struct {
+			Identifier string `json:"identifier"`
+		} `json:"levels"`
+	}
+	if err := json.Unmarshal(flat, &project); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, l := range project.Levels {
+		got = append(got, l.Identifier)
+	}
+	if want := []string{"A", "B", "C", "D"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Levels are %v, want %v", got, want)
+	}
+
+	single := []byte(`{"levels":[{"identifier":"A"}]}`)
+	if flat, err := flattenWorlds(single); err != nil || string(flat) != string(single) {
+		t.Errorf("Project without worlds changed to %s (%v)", flat, err)
+	}
+}
+
+func TestReadExternalLevel(t *testing.T) {
+	data, err := fs.ReadFile(assets, "assets/maps.ldtk")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Save the first level in its own file like LDtk does
+	var project map[string]json.RawMessage
+	if err := json.Unmarshal(data, &project); err != nil {
+		t.Fatal(err)
+	}
+	var levels []map[string]json.RawMessage
+	if err := json.Unmarshal(project["levels"], &levels); err != nil {
+		t.Fatal(err)
+	}
+	levelData, err := json.Marshal(levels[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	levels[0]["layerInstances"] = json.RawMessage("null")
+	levels[0]["externalRelPath"] = json.RawMessage(`"maps/Level_0.ldtkl"`)
+	if project["levels"], err = json.Marshal(levels); err != nil {
+		t.Fatal(err)
+	}
+	if data, err = json.Marshal(project); err != nil {
+		t.Fatal(err)
+	}
+
+	var read []string
+	readFile := func(name string) ([]byte, error) {
+		read = append(read, name)
+		return levelData, nil
+	}
+	maps, err := ReadLDTK("assets/maps.ldtk", data, readFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	level := maps.Levels()[0]
+	if level.Loaded || len(level.Layers) != 0 {
+		t.Errorf("External level has %d layers before it's read", len(level.Layers))
+	}
+
+	if err := maps.LoadLevel(level); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"assets/maps/Level_0.ldtkl"}; !reflect.DeepEqual(read, want) {
+		t.Errorf("Read files %v, want %v", read, want)
+	}
+	if !level.Loaded || len(level.Layers) == 0 {
+		t.Fatal("Level has no layers after it's read")
+	}
+	if err := maps.LoadLevel(level); err != nil || len(read) != 1 {
+		t.Errorf("Loaded level was read again (%v)", err)
+	}
+
+	// Tiles point at the project's tilesets
+	tilesets := make(map[string]*MapTileset)
+	for _, l := range maps.Levels() {
+		for _, layer := range l.Layers {
+			for _, tile := range layer.Tiles {
+				if tile.Tileset == nil {
+					t.Fatalf("Tile on layer %s has no tileset", layer.Identifier)
+				}
+				if ts, ok := tilesets[tile.Tileset.Path]; ok && ts != tile.Tileset {
+					t.Fatalf("Layer %s doesn't share the project's tileset", layer.Identifier)
+				}
+				tilesets[tile.Tileset.Path] = tile.Tileset
+			}
+		}
+	}
+	if len(tilesets) != 1 || tilesets["tileset.png"] == nil {
+		t.Errorf("Tiles are from tilesets %v, want just tileset.png", tilesets)
+	}
+}
@@ -0,0 +1,260 @@
+// Copyright 2021 Siôn le Roux.  All rights reserved.
+// Use of this source code is subject to an MIT-style
+// licence which can be found in the LICENSE file.
+
+package cr1ckt
+
+import (
+	"image"
+	"image/color"
+)
+
+// MapSource is where levels come from, like an LDtk project or a Tiled map,
+// it gives them to the game in the game's own types so the rest of the game
+// doesn't need to know which editor they were made in
+type MapSource interface {
+	// Levels returns every level, in the order they're played
+	Levels() []*MapLevel
+	// LoadLevel reads the layers of a level if they weren't read with the
+	// rest, it does nothing for levels that are already loaded or that come
+	// from another source
+	LoadLevel(level *MapLevel) error
+}
+
+// MapSources is several map sources whose levels are played one after
+// another, e.g. the base campaign and then the bonus packs
+type MapSources []MapSource
+
+// Levels returns the levels of every source, in order
+func (ms MapSources) Levels() []*MapLevel {
+	var levels []*MapLevel
+	for _, s := range ms {
+		levels = append(levels, s.Levels()...)
+	}
+	return levels
+}
+
+// LoadLevel loads a level from whichever source it came from
+func (ms MapSources) LoadLevel(level *MapLevel) error {
+	for _, s := range ms {
+		if level.Loaded {
+			return nil
+		}
+		if err := s.LoadLevel(level); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MapLevel is one level of the game
+type MapLevel struct {
+	Identifier string
+	World      string // Levels in the same world can be next to each other
+	Connected  bool   // Whether the levels of the world are laid out next to each other
+	WorldX     int    // Position of the level in its world
+	WorldY     int
+	Width      int // Size of the level in pixels
+	Height     int
+	BGColor    color.Color
+	Layers     []*MapLayer // First is on top, like in LDtk
+	Loaded     bool        // Whether the layers have been read, see MapSource.LoadLevel
+	Properties
+}
+
+// MapLayerType is what a MapLayer has in it
+type MapLayerType string
+
+const (
+	// MapLayerTiles is a layer of tiles, which are tile, auto-tile and IntGrid
+	// layers in LDtk and tile layers in Tiled
+	MapLayerTiles MapLayerType = "Tiles"
+	// MapLayerEntities is a layer of entities, which are object layers in
+	// Tiled
+	MapLayerEntities MapLayerType = "Entities"
+)
+
+// MapLayer is one layer of a level
+type MapLayer struct {
+	Identifier      string
+	Type            MapLayerType
+	GridSize        int // Width and height of the tiles in pixels
+	OffsetX         int // How far the layer is moved from where the level is
+	OffsetY         int
+	Visible         bool
+	Opacity         float64 // From 0 to 1
+	ParallaxX       float64 // From -1 to 1, 0 scrolls with the level
+	ParallaxY       float64
+	ParallaxScaling bool // Whether it shrinks as if further away too
+	Tiles           []*MapTile
+	Entities        []*MapEntity
+}
+
+// EntityByIdentifier returns the first entity on the layer with the given
+// identifier, or nil if there isn't one
+func (l *MapLayer) EntityByIdentifier(identifier string) *MapEntity {
+	for _, e := range l.Entities {
+		if e.Identifier == identifier {
+			return e
+		}
+	}
+	return nil
+}
+
+// TileAt returns the tile on the layer covering a point in the level, or nil
+// if there isn't one there
+func (l *MapLayer) TileAt(x, y int) *MapTile {
+	for _, t := range l.Tiles {
+		if image.Pt(x, y).In(image.Rect(
+			t.Position[0], t.Position[1],
+			t.Position[0]+l.GridSize, t.Position[1]+l.GridSize,
+		)) {
+			return t
+		}
+	}
+	return nil
+}
+
+// MapTileset is a tileset image that tiles are cut out of
+type MapTileset struct {
+	Path string // In the assets folder
+}
+
+// MapTile is one tile on a tile layer
+type MapTile struct {
+	ID       int   // Which tile of the tileset it is, counting from 0
+	Position []int // Position in the level in pixels (x, y)
+	Src      image.Rectangle
+	Tileset  *MapTileset
+	FlipX    bool
+	FlipY    bool
+}
+
+// MapEntity is something placed in a level, like the cricket or a seed, they
+// are LDtk entities and Tiled objects
+type MapEntity struct {
+	Identifier string
+	Iid        string    // Unique instance ID, empty for LDtk projects older than 1.0
+	Position   []int     // Position of the pivot in pixels (x, y)
+	Width      int       // Size in pixels
+	Height     int       // Size in pixels
+	Pivot      []float64 // Where the position is in the entity, 0.5, 0.5 is the middle
+	Tile       *EntityTile
+	Properties
+}
+
+// EntityTile is a tile an entity is drawn with, it can be any part of a
+// tileset image
+type EntityTile struct {
+	Tileset *MapTileset
+	Src     image.Rectangle
+}
+
+// EntityRef is a reference to another entity in a field, by its instance ID
+type EntityRef string
+
+// Property is a custom field of a level or entity, its Value is nil when it
+// isn't set, a float64 for numbers, a bool, a string, an *EntityTile, an
+// EntityRef or a []interface{} of those for arrays
+type Property struct {
+	Identifier string
+	Value      interface{}
+}
+
+// IsNull returns whether the property isn't set
+func (p *Property) IsNull() bool {
+	return p.Value == nil
+}
+
+// AsInt returns the property's value as an int
+func (p *Property) AsInt() int {
+	return int(p.AsFloat64())
+}
+
+// AsFloat64 returns the property's value as a float64
+func (p *Property) AsFloat64() float64 {
+	return p.Value.(float64)
+}
+
+// AsString returns the property's value as a string
+func (p *Property) AsString() string {
+	return p.Value.(string)
+}
+
+// AsBool returns the property's value as a bool
+func (p *Property) AsBool() bool {
+	return p.Value.(bool)
+}
+
+// values returns the property's value as a list, which is just the value on
+// its own if it isn't an array
+func (p *Property) values() []interface{} {
+	if p.IsNull() {
+		return nil
+	}
+	if v, ok := p.Value.([]interface{}); ok {
+		return v
+	}
+	return []interface{}{p.Value}
+}
+
+// AsTiles returns the tiles in the property, which can hold either one tile
+// or an array of them
+func (p *Property) AsTiles() []*EntityTile {
+	var tiles []*EntityTile
+	for _, v := range p.values() {
+		if t, ok := v.(*EntityTile); ok && t.Tileset != nil {
+			tiles = append(tiles, t)
+		}
+	}
+	return tiles
+}
+
+// AsRefs returns the instance IDs of the entities referenced by the property,
+// which can hold either one reference or an array of them
+func (p *Property) AsRefs() []string {
+	var iids []string
+	for _, v := range p.values() {
+		if r, ok := v.(EntityRef); ok {
+			iids = append(iids, string(r))
+		}
+	}
+	return iids
+}
+
+// Properties are the custom fields of a level or entity
+type Properties []*Property
+
+// PropertyByIdentifier returns the property with the given identifier, or nil
+// if there isn't one
+func (ps Properties) PropertyByIdentifier(identifier string) *Property {
+	for _, p := range ps {
+		if p.Identifier == identifier {
+			return p
+		}
+	}
+	return nil
+}
+
+// entityRect returns the rectangle an entity covers in the level, where it's
+// drawn and where LDtk shows it, the entity's position is where its pivot is
+func entityRect(e *MapEntity) image.Rectangle {
+	pos := image.Pt(e.Position[0], e.Position[1])
+	if len(e.Pivot) == 2 {
+		pos = pos.Sub(image.Pt(
+			int(float64(e.Width)*e.Pivot[0]),
+			int(float64(e.Height)*e.Pivot[1]),
+		))
+	}
+	return image.Rect(0, 0, e.Width, e.Height).Add(pos)
+}
+
+// EntityRefs returns the instance IDs of the entities referenced by an entity
+// reference field, which can hold either one reference or an array of them
+func EntityRefs(e *MapEntity, identifier string) []string {
+	p := e.PropertyByIdentifier(identifier)
+	if p == nil {
+		return nil
+	}
+	return p.AsRefs()
+}
@@ -0,0 +1,28 @@
+package cr1ckt
+
+import (
+	"image"
+	"testing"
+)
+
+func TestEntityRect(t *testing.T) {
+	cases := []struct {
+		pivot   []float64
+		want    image.Rectangle
+		comment string
+	}{
+		{nil, image.Rect(64, 32, 80, 64), "no pivot"},
+		{[]float64{0, 0}, image.Rect(64, 32, 80, 64), "top left pivot"},
+		{[]float64{0.5, 0.5}, image.Rect(56, 16, 72, 48), "centred pivot"},
+		{[]float64{0.5, 1}, image.Rect(56, 0, 72, 32), "bottom middle pivot"},
+	}
+	for _, c := range cases {
+		e := &MapEntity{Position: []int{64, 32}, Width: 16, Height: 32, Pivot: c.pivot}
+		if got := entityRect(e); got != c.want {
+			t.Errorf("Entity with %s covers %v, want %v", c.comment, got, c.want)
+		}
+		if got := NewCheckpoint(e).Hitbox(); got != c.want {
+			t.Errorf("Checkpoint with %s covers %v, want %v", c.comment, got, c.want)
+		}
+	}
+}
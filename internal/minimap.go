@@ -0,0 +1,95 @@
+// Copyright 2021 Siôn le Roux.  All rights reserved.
+// Use of this source code is subject to an MIT-style
+// licence which can be found in the LICENSE file.
+
+package cr1ckt
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+// minimapSize is the size of the longest side of the minimap in pixels
+const minimapSize = 120
+
+// minimapMargin is the space between the minimap and the edge of the screen
+const minimapMargin = 8
+
+// renderMinimap draws the rendered layers of the current level shrunk down to
+// fit in the minimap
+func (g *Game) renderMinimap() {
+	level := g.Levels[g.Level]
+	scale := minimapScale(level.Width, level.Height)
+	if g.minimap != nil {
+		g.minimap.Dispose()
+	}
+	g.minimap = ebiten.NewImage(
+		int(math.Ceil(float64(level.Width)*scale)),
+		int(math.Ceil(float64(level.Height)*scale)),
+	)
+	g.minimap.Fill(level.BGColor)
+	for _, layer := range g.TileRenderer.RenderedLayers {
+		for _, c := range layer.Chunks {
+			op := &ebiten.DrawImageOptions{Filter: ebiten.FilterLinear}
+			op.GeoM.Translate(
+				float64(c.Rect.Min.X+layer.Layer.OffsetX),
+				float64(c.Rect.Min.Y+layer.Layer.OffsetY),
+			)
+			op.GeoM.Scale(scale, scale)
+			op.ColorScale.ScaleAlpha(float32(layer.Layer.Opacity))
+			g.minimap.DrawImage(c.Image, op)
+		}
+	}
+}
+
+// minimapScale returns how much to scale a level of the given size down by to
+// fit in the minimap
+func minimapScale(w, h int) float64 {
+	return minimapSize / math.Max(float64(w), float64(h))
+}
+
+// minimapShown returns whether the minimap should be drawn right now
+func (g *Game) minimapShown() bool {
+	return ShowMinimap && !HardMode && g.minimap != nil && g.intro == nil
+}
+
+// drawMinimap draws the minimap in the bottom right corner of the screen, with
+// the exits and the cricket marked on it
+func (g *Game) drawMinimap(screen *ebiten.Image) {
+	level := g.Levels[g.Level]
+	scale := minimapScale(level.Width, level.Height)
+	size := g.minimap.Bounds().Size()
+	corner := image.Pt(g.Width, g.Height).Sub(size).Sub(image.Pt(minimapMargin, minimapMargin))
+
+	ebitenutil.DrawRect(screen,
+		float64(corner.X-1), float64(corner.Y-1),
+		float64(size.X+2), float64(size.Y+2),
+		color.White,
+	)
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(corner.X), float64(corner.Y))
+	screen.DrawImage(g.minimap, op)
+
+	dot := func(p image.Point, clr color.Color) {
+		ebitenutil.DrawRect(screen,
+			float64(corner.X)+float64(p.X)*scale-1,
+			float64(corner.Y)+float64(p.Y)*scale-1,
+			3, 3, clr,
+		)
+	}
+	for _, exit := range g.exits {
+		dot(exit.Rect.Min.Add(exit.Rect.Size().Div(2)), color.RGBA{0xf2, 0xc1, 0x3a, 0xff})
+	}
+	hitbox := g.Cricket.Hitbox()
+	dot(hitbox.Min.Add(hitbox.Size().Div(2)), color.RGBA{0xe0, 0x30, 0x30, 0xff})
+}
+
+// overviewHeld returns whether the player is holding the overview key to see
+// the whole level, which isn't allowed in hard mode
+func (g *Game) overviewHeld() bool {
+	return !HardMode && ebiten.IsKeyPressed(ebiten.KeyTab)
+}
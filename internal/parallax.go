@@ -0,0 +1,157 @@
+// Copyright 2021 Siôn le Roux.  All rights reserved.
+// Use of this source code is subject to an MIT-style
+// licence which can be found in the LICENSE file.
+
+package cr1ckt
+
+import (
+	"encoding/json"
+	"image"
+	"io/ioutil"
+	"log"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// ParallaxFile is the manifest of parallax background sets, each one is a list
+// of layers from back to front, the "default" set is used for levels that
+// don't choose one
+const ParallaxFile = "assets/parallax.json"
+
+// ParallaxDefault is the set of layers used when a level doesn't choose one
+const ParallaxDefault = "default"
+
+// ParallaxLayer is one image in a level's background that scrolls at its own
+// rate behind the tiles
+type ParallaxLayer struct {
+	Image       string  `json:"image"`
+	Scroll      float64 `json:"scroll"`      // 1 moves with the tiles, 0 stays still
+	AutoScrollX float64 `json:"autoScrollX"` // Pixels per tick it drifts by itself
+	AutoScrollY float64 `json:"autoScrollY"`
+	Repeat      bool    `json:"repeat"` // Tile the image to fill the screen
+	image       *ebiten.Image
+	x, y        float64 // How far it has drifted so far
+}
+
+// Update drifts the layer along by its autoscroll speed
+func (l *ParallaxLayer) Update() {
+	l.x += l.AutoScrollX
+	l.y += l.AutoScrollY
+	if l.Repeat {
+		w, h := l.image.Size()
+		l.x = math.Mod(l.x, float64(w))
+		l.y = math.Mod(l.y, float64(h))
+	}
+}
+
+// loadParallaxSets reads the parallax manifest from the embedded FS
+func loadParallaxSets(name string) map[string][]ParallaxLayer {
+	log.Printf("loading %s\n", name)
+
+	file, err := assets.Open(name)
+	if err != nil {
+		log.Fatalf("error opening file %s: %v\n", name, err)
+	}
+	defer file.Close()
+
+	data, err := ioutil.ReadAll(file)
+	if err != nil {
+		log.Fatalf("error reading from file %s: %v\n", name, err)
+	}
+
+	var sets map[string][]ParallaxLayer
+	if err := json.Unmarshal(data, &sets); err != nil {
+		log.Fatalf("error parsing file %s as parallax manifest: %v\n", name, err)
+	}
+	return sets
+}
+
+// parallaxName returns the name of the parallax set for the current level,
+// which is the set named in its Parallax level field, or the set with the
+// same name as the level, or the default set
+func (g *Game) parallaxName() string {
+	level := g.Levels[g.Level]
+	name := ParallaxDefault
+	if _, ok := g.parallaxSets[level.Identifier]; ok {
+		name = level.Identifier
+	}
+	if p := level.PropertyByIdentifier("Parallax"); p != nil && !p.IsNull() {
+		name = p.AsString()
+	}
+	return name
+}
+
+// startParallax loads the parallax layers for the current level and lines
+// them up with it
+func (g *Game) startParallax() {
+	level := g.Levels[g.Level]
+	g.parallaxSet = g.parallaxName()
+	g.parallax = g.loadParallax(g.parallaxSet)
+	g.parallaxAt = image.Rect(0, 0, level.Width, level.Height).
+		Add(image.Pt(level.WorldX, level.WorldY))
+}
+
+// loadParallax returns the layers of the parallax set with the given name
+func (g *Game) loadParallax(name string) []*ParallaxLayer {
+	set, ok := g.parallaxSets[name]
+	if !ok {
+		log.Printf("Level %s has unknown parallax set %q\n", g.Levels[g.Level].Identifier, name)
+		return nil
+	}
+
+	var layers []*ParallaxLayer
+	for _, l := range set {
+		l := l
+		if g.parallaxImgs[l.Image] == nil {
+			g.parallaxImgs[l.Image] = loadImage("assets/" + l.Image)
+		}
+		l.image = g.parallaxImgs[l.Image]
+		layers = append(layers, &l)
+	}
+	return layers
+}
+
+// updateParallax drifts all the autoscrolling parallax layers along
+func (g *Game) updateParallax() {
+	for _, l := range g.parallax {
+		l.Update()
+	}
+}
+
+// drawParallax fills the camera surface with the level's background colour
+// and draws the parallax layers over it; the layers line up with the level
+// they were started in when the camera is in the middle of it, so one as big
+// as the level always covers the screen. It works in world pixels so the
+// layers carry on smoothly into neighbouring levels.
+func (g *Game) drawParallax() {
+	level := g.Levels[g.Level]
+	g.cam.Surface.Fill(level.BGColor)
+	origin := image.Pt(level.WorldX, level.WorldY)
+	at := g.parallaxAt.Sub(origin) // In the current level's pixels
+	cx := float64(at.Min.X) + float64(at.Dx())/2
+	cy := float64(at.Min.Y) + float64(at.Dy())/2
+	sw, sh := g.cam.Surface.Size()
+
+	for _, l := range g.parallax {
+		x := snap(float64(at.Min.X) + (g.cam.X-cx)*(1-l.Scroll) + l.x)
+		y := snap(float64(at.Min.Y) + (g.cam.Y-cy)*(1-l.Scroll) + l.y)
+		if !l.Repeat {
+			g.cam.Surface.DrawImage(l.image, g.cam.GetTranslation(x, y))
+			continue
+		}
+
+		// Start from the copy just off the top left and fill the surface
+		w, h := l.image.Size()
+		left, top := g.cam.GetTranslation(x, y).GeoM.Apply(0, 0)
+		left = math.Mod(left, float64(w)) - float64(w)
+		top = math.Mod(top, float64(h)) - float64(h)
+		for ty := top; ty < float64(sh); ty += float64(h) {
+			for tx := left; tx < float64(sw); tx += float64(w) {
+				op := &ebiten.DrawImageOptions{}
+				op.GeoM.Translate(tx, ty)
+				g.cam.Surface.DrawImage(l.image, op)
+			}
+		}
+	}
+}
@@ -0,0 +1,220 @@
+// Copyright 2021 Siôn le Roux.  All rights reserved.
+// Use of this source code is subject to an MIT-style
+// licence which can be found in the LICENSE file.
+
+package cr1ckt
+
+import (
+	"image"
+	"image/color"
+	"log"
+	"math"
+	"math/rand"
+
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+// particlePoolSize is how many particles can be alive at once, when the pool
+// is full the oldest particles are reused
+const particlePoolSize = 512
+
+// ParticleKind describes how a type of particle looks and moves
+type ParticleKind struct {
+	Colors  []color.RGBA
+	Size    float64
+	Speed   float64 // Starting speed in pixels per tick
+	Angle   float64 // Direction of travel in radians, 0 is right, -π/2 is up
+	Spread  float64 // How far the direction can be off Angle either way
+	Gravity float64 // Added to the downwards speed every tick
+	Drag    float64 // How much speed is kept every tick, 1 is none lost
+	Wobble  float64 // How much the particle wanders sideways
+	Life    int     // Ticks the particle lives for, give or take a quarter
+}
+
+// Kinds of particles in the game, the names of the ambient ones can be used in
+// the Kind field of an Emitter entity
+var (
+	ParticleDust = ParticleKind{
+		Colors: []color.RGBA{{0xb9, 0x8b, 0x4e, 0xff}, {0xd8, 0xc0, 0x90, 0xff}},
+		Size:   2, Speed: 1.2, Angle: -math.Pi / 2, Spread: math.Pi / 2.2,
+		Gravity: 0.05, Drag: 0.9, Life: 25,
+	}
+	ParticleSplash = ParticleKind{
+		Colors: []color.RGBA{{0x9c, 0xd6, 0xf0, 0xff}, {0xff, 0xff, 0xff, 0xff}},
+		Size:   3, Speed: 3, Angle: -math.Pi / 2, Spread: math.Pi / 4,
+		Gravity: 0.15, Drag: 0.98, Life: 40,
+	}
+	ParticlePollen = ParticleKind{
+		Colors: []color.RGBA{{0xf2, 0xc1, 0x3a, 0xff}, {0xff, 0xe8, 0x80, 0xff}},
+		Size:   2, Speed: 0.8, Angle: -math.Pi / 2, Spread: math.Pi,
+		Gravity: -0.005, Drag: 0.97, Wobble: 0.1, Life: 90,
+	}
+	ParticleFireflies = ParticleKind{
+		Colors: []color.RGBA{{0xd8, 0xff, 0x60, 0xff}, {0xa0, 0xe0, 0x40, 0xff}},
+		Size:   2, Speed: 0.3, Spread: math.Pi,
+		Drag: 1, Wobble: 0.08, Life: 240,
+	}
+	ParticleLeaves = ParticleKind{
+		Colors: []color.RGBA{{0xd0, 0x70, 0x30, 0xff}, {0xa0, 0x50, 0x20, 0xff}, {0x90, 0xa0, 0x30, 0xff}},
+		Size:   3, Speed: 0.3, Angle: math.Pi / 2, Spread: math.Pi / 4,
+		Gravity: 0.01, Drag: 0.97, Wobble: 0.15, Life: 300,
+	}
+)
+
+// particleKinds are the kinds an Emitter entity can use, by name
+var particleKinds = map[string]ParticleKind{
+	"Dust":      ParticleDust,
+	"Splash":    ParticleSplash,
+	"Pollen":    ParticlePollen,
+	"Fireflies": ParticleFireflies,
+	"Leaves":    ParticleLeaves,
+}
+
+// Particle is a single speck of dust, drop of water, grain of pollen, etc.
+type Particle struct {
+	X, Y, VX, VY float64
+	Age, Life    int
+	Color        color.RGBA
+	Kind         *ParticleKind
+}
+
+// Alive returns whether the particle still needs updating and drawing
+func (p *Particle) Alive() bool {
+	return p.Age < p.Life
+}
+
+// Particles is a fixed pool of particles that reuses its slots instead of
+// allocating new particles all the time
+type Particles struct {
+	pool [particlePoolSize]Particle
+	next int
+	tick int
+}
+
+// Emit starts n new particles of the given kind at a position in the level
+func (ps *Particles) Emit(kind *ParticleKind, n int, x, y float64) {
+	for i := 0; i < n; i++ {
+		angle := kind.Angle + (rand.Float64()*2-1)*kind.Spread
+		speed := kind.Speed * (0.5 + rand.Float64())
+		ps.pool[ps.next] = Particle{
+			X: x, Y: y,
+			VX:    math.Cos(angle) * speed,
+			VY:    math.Sin(angle) * speed,
+			Life:  kind.Life*3/4 + rand.Intn(kind.Life/2+1),
+			Color: kind.Colors[rand.Intn(len(kind.Colors))],
+			Kind:  kind,
+		}
+		ps.next = (ps.next + 1) % len(ps.pool)
+	}
+}
+
+// Update moves all the live particles along
+func (ps *Particles) Update() {
+	ps.tick++
+	for i := range ps.pool {
+		p := &ps.pool[i]
+		if !p.Alive() {
+			continue
+		}
+		p.Age++
+		p.VX = p.VX*p.Kind.Drag + (rand.Float64()*2-1)*p.Kind.Wobble
+		p.VY = p.VY*p.Kind.Drag + p.Kind.Gravity
+		p.X += p.VX
+		p.Y += p.VY
+	}
+}
+
+// Draw draws all the live particles to the camera surface, fading them out at
+// the end of their lives
+func (ps *Particles) Draw(g *Game) {
+	for i := range ps.pool {
+		p := &ps.pool[i]
+		if !p.Alive() {
+			continue
+		}
+		clr := p.Color
+		if left := p.Life - p.Age; left < p.Life/3 {
+			clr.A = uint8(int(clr.A) * left * 3 / p.Life)
+			clr.R = uint8(int(clr.R) * int(clr.A) / 0xff)
+			clr.G = uint8(int(clr.G) * int(clr.A) / 0xff)
+			clr.B = uint8(int(clr.B) * int(clr.A) / 0xff)
+		}
+		size := p.Kind.Size
+		x, y := g.cam.GetTranslation(p.X-size/2, p.Y-size/2).GeoM.Apply(0, 0)
+		ebitenutil.DrawRect(g.cam.Surface, x, y, size, size, clr)
+	}
+}
+
+// Clear kills all the particles, e.g. when the level changes
+func (ps *Particles) Clear() {
+	for i := range ps.pool {
+		ps.pool[i].Life = 0
+	}
+}
+
+// Shift moves all the particles by the given offset, e.g. when the cricket
+// crosses into a neighbouring level and everything moves to its pixels
+func (ps *Particles) Shift(offset image.Point) {
+	for i := range ps.pool {
+		ps.pool[i].X += float64(offset.X)
+		ps.pool[i].Y += float64(offset.Y)
+	}
+}
+
+// Emitter is an area from an "Emitter" entity in LDtk that gives off ambient
+// particles of the kind in its Kind field, Rate of them per second
+type Emitter struct {
+	Rect image.Rectangle
+	Kind *ParticleKind
+	Rate int
+	tick int
+}
+
+func init() {
+	RegisterEntity("Emitter", func(g *Game, e *MapEntity) Entity {
+		if em := NewEmitter(e); em != nil {
+			return em
+		}
+		return nil
+	})
+}
+
+// NewEmitter returns a new Emitter covering the given LDtk entity, or nil if
+// its Kind isn't one the game knows
+func NewEmitter(e *MapEntity) *Emitter {
+	em := &Emitter{Rect: entityRect(e), Rate: 1}
+	name := "Fireflies"
+	if p := e.PropertyByIdentifier("Kind"); p != nil && !p.IsNull() {
+		name = p.AsString()
+	}
+	kind, ok := particleKinds[name]
+	if !ok {
+		log.Printf("Emitter has unknown particle kind %q\n", name)
+		return nil
+	}
+	em.Kind = &kind
+	if p := e.PropertyByIdentifier("Rate"); p != nil && !p.IsNull() && p.AsInt() > 0 {
+		em.Rate = p.AsInt()
+	}
+	return em
+}
+
+// Hitbox returns the area the emitter gives off particles in
+func (em *Emitter) Hitbox() image.Rectangle {
+	return em.Rect
+}
+
+// Update gives off particles at random places in the emitter's area
+func (em *Emitter) Update(g *Game) {
+	em.tick++
+	// Spread the particles evenly over each second
+	for n := em.tick * em.Rate / 60; n > (em.tick-1)*em.Rate/60; n-- {
+		g.particles.Emit(em.Kind, 1,
+			float64(em.Rect.Min.X)+rand.Float64()*float64(em.Rect.Dx()),
+			float64(em.Rect.Min.Y)+rand.Float64()*float64(em.Rect.Dy()),
+		)
+	}
+}
+
+// pollenTicks is how often the exits give off a burst of pollen
+const pollenTicks = 90
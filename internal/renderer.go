@@ -2,11 +2,11 @@ package cr1ckt
 
 import (
 	"image"
+	"math"
 	"path"
-	"sort"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/solarlune/ldtkgo"
+	camera "github.com/melonfunction/ebiten-camera"
 
 	_ "image/png" // Importing for loading PNGs
 )
@@ -21,26 +21,88 @@ type EmbedLoader struct {
 	BasePath string
 }
 
-// LoadTileset loads an LDtk tileset image from the embedded FS
+// LoadTileset loads a tileset image from the embedded FS
 func (l *EmbedLoader) LoadTileset(tileSetPath string) *ebiten.Image {
 	return loadImage(path.Join(l.BasePath, tileSetPath))
 }
 
-// RenderedLayer represents an LDtk.Layer that was rendered out to an *ebiten.Image.
+// TileChunkSize is the width and height of the chunks levels are rendered in.
+// Levels no bigger than this are rendered as one image like before, bigger ones
+// are split up so they don't go over GPU texture limits.
+const TileChunkSize = 512
+
+// TileChunk is one square of a RenderedLayer, only chunks with tiles in them
+// are made.
+type TileChunk struct {
+	Rect  image.Rectangle // Where the chunk is in the level
+	Image *ebiten.Image   // The image that was rendered out
+}
+
+// RenderedLayer represents a MapLayer that was rendered out to chunks of *ebiten.Images.
 type RenderedLayer struct {
-	Image *ebiten.Image // The image that was rendered out
-	Layer *ldtkgo.Layer // The layer used to render the image
+	Chunks []*TileChunk    // The chunks that were rendered out, in the order they were made
+	Layer  *MapLayer       // The layer used to render the images
+	Origin image.Point     // Where the level is compared to the current one
+	bounds image.Rectangle // The level the layer is in
+	chunks map[image.Point]*TileChunk
+}
+
+// chunk returns the chunk at the given chunk coordinates, making it if it
+// doesn't exist yet, or nil if it would be outside the level.
+func (rl *RenderedLayer) chunk(p image.Point) *TileChunk {
+	if c, ok := rl.chunks[p]; ok {
+		return c
+	}
+	rect := image.Rect(0, 0, TileChunkSize, TileChunkSize).
+		Add(p.Mul(TileChunkSize)).
+		Intersect(rl.bounds)
+	if rect.Empty() {
+		return nil
+	}
+	c := &TileChunk{Rect: rect, Image: ebiten.NewImage(rect.Dx(), rect.Dy())}
+	rl.chunks[p] = c
+	rl.Chunks = append(rl.Chunks, c)
+	return c
 }
 
-// TileRenderer is a struct that renders LDtk levels to *ebiten.Images.
+// draw draws a tile to every chunk it overlaps; opt should put the tile where
+// it goes in the level and bounds is the area it covers there.
+func (rl *RenderedLayer) draw(tile *ebiten.Image, opt *ebiten.DrawImageOptions, bounds image.Rectangle) {
+	for y := floorDiv(bounds.Min.Y, TileChunkSize); y <= floorDiv(bounds.Max.Y-1, TileChunkSize); y++ {
+		for x := floorDiv(bounds.Min.X, TileChunkSize); x <= floorDiv(bounds.Max.X-1, TileChunkSize); x++ {
+			c := rl.chunk(image.Pt(x, y))
+			if c == nil {
+				continue
+			}
+			chunkOpt := *opt
+			chunkOpt.GeoM.Translate(float64(-c.Rect.Min.X), float64(-c.Rect.Min.Y))
+			c.Image.DrawImage(tile, &chunkOpt)
+		}
+	}
+}
+
+// floorDiv divides a by b rounding down, so tiles left of or above the level
+// land in the right chunk.
+func floorDiv(a, b int) int {
+	if a < 0 {
+		return -((b - 1 - a) / b)
+	}
+	return a / b
+}
+
+// snap rounds a position to a whole pixel, which keeps the pixel art crisp.
+func snap(v float64) float64 {
+	return math.Round(v)
+}
+
+// TileRenderer is a struct that renders levels to *ebiten.Images.
 type TileRenderer struct {
 	Tilesets       map[string]*ebiten.Image
-	CurrentTileset string
 	RenderedLayers []*RenderedLayer
 	Loader         TilesetLoader // Loader for the renderer; defaults to a DiskLoader instance, though this can be switched out with something else as necessary.
 }
 
-// NewTileRenderer creates a new Renderer instance. TilesetLoader should be an instance of a struct designed to return *ebiten.Images for each Tileset requested (by path relative to the LDtk project file).
+// NewTileRenderer creates a new Renderer instance. TilesetLoader should be an instance of a struct designed to return *ebiten.Images for each Tileset requested (by path relative to the assets folder).
 func NewTileRenderer(loader TilesetLoader) *TileRenderer {
 	return &TileRenderer{
 		Tilesets:       map[string]*ebiten.Image{},
@@ -52,124 +114,158 @@ func NewTileRenderer(loader TilesetLoader) *TileRenderer {
 // Clear clears the renderer's Result.
 func (er *TileRenderer) Clear() {
 	for _, layer := range er.RenderedLayers {
-		layer.Image.Dispose()
+		for _, c := range layer.Chunks {
+			c.Image.Dispose()
+		}
 	}
 	er.RenderedLayers = []*RenderedLayer{}
 }
 
-// beginLayer gets called when necessary between rendering indidvidual Layers of a Level.
-func (er *TileRenderer) beginLayer(layer *ldtkgo.Layer, w, h int) {
-
-	_, exists := er.Tilesets[layer.Tileset.Path]
-
-	if !exists {
-		er.Tilesets[layer.Tileset.Path] = er.Loader.LoadTileset(layer.Tileset.Path)
+// tileset returns the image for a tileset, loading it the first time it's used.
+func (er *TileRenderer) tileset(ts *MapTileset) *ebiten.Image {
+	if _, exists := er.Tilesets[ts.Path]; !exists {
+		er.Tilesets[ts.Path] = er.Loader.LoadTileset(ts.Path)
 	}
+	return er.Tilesets[ts.Path]
+}
 
-	er.CurrentTileset = layer.Tileset.Path
-
-	renderedImage := ebiten.NewImage(w, h)
-
-	er.RenderedLayers = append(er.RenderedLayers, &RenderedLayer{Image: renderedImage, Layer: layer})
-
+// beginLayer gets called when necessary between rendering indidvidual Layers of a Level.
+func (er *TileRenderer) beginLayer(layer *MapLayer, w, h int, origin image.Point) *RenderedLayer {
+	rendered := &RenderedLayer{
+		Layer:  layer,
+		Origin: origin,
+		bounds: image.Rect(0, 0, w, h),
+		chunks: map[image.Point]*TileChunk{},
+	}
+	er.RenderedLayers = append(er.RenderedLayers, rendered)
+	return rendered
 }
 
-// renderTile gets called by LDtkgo.Layer.RenderTiles(), and is currently provided the following arguments to handle rendering each tile in a Layer:
-// x, y = position of the drawn tile
-// srcX, srcY = position on the source tilesheet of the specified tile
-// srcW, srcH = width and height of the tile
-// flipBit = the flip bit of the tile; if the first bit is set, it should flip horizontally. If the second is set, it should flip vertically.
-func (er *TileRenderer) renderTile(x, y, srcX, srcY, srcW, srcH int, flipBit byte) {
+// renderTile draws the src part of a tileset image to the dst area of a
+// layer, flipped and stretched as needed. Positions are inside the layer, its
+// offset is added when it's drawn so offset tiles aren't cut off.
+func (rl *RenderedLayer) renderTile(tileset *ebiten.Image, src, dst image.Rectangle, flipX, flipY bool) {
 
 	// Subimage the Tile from the Tileset
-	tile := er.Tilesets[er.CurrentTileset].SubImage(image.Rect(srcX, srcY, srcX+srcW, srcY+srcH)).(*ebiten.Image)
+	tile := tileset.SubImage(src).(*ebiten.Image)
 
 	opt := &ebiten.DrawImageOptions{}
 
 	// We have to offset the tile to be centered before flipping
-	opt.GeoM.Translate(float64(-srcW/2), float64(-srcH/2))
+	opt.GeoM.Translate(float64(-src.Dx())/2, float64(-src.Dy())/2)
 
-	// Handle flipping; first bit in byte is horizontal flipping, second is vertical flipping.
-
-	if flipBit&1 > 0 {
+	if flipX {
 		opt.GeoM.Scale(-1, 1)
 	}
-	if flipBit&2 > 0 {
+	if flipY {
 		opt.GeoM.Scale(1, -1)
 	}
 
-	// Undo offsetting
-	opt.GeoM.Translate(float64(srcW/2), float64(srcH/2))
+	// Stretch to the destination size
+	opt.GeoM.Scale(float64(dst.Dx())/float64(src.Dx()), float64(dst.Dy())/float64(src.Dy()))
 
-	// Move tile to final position; note that slightly unlike LDtk, layer offsets in LDtk-Go are added directly into the final tiles' X and Y positions. This means that with this renderer,
-	// if a layer's offset pushes tiles outside of the layer's render Result image, they will be cut off. On LDtk, the tiles are still rendered, of course.
-	opt.GeoM.Translate(float64(x), float64(y))
+	// Undo offsetting and move tile to final position
+	opt.GeoM.Translate(float64(dst.Min.X)+float64(dst.Dx())/2, float64(dst.Min.Y)+float64(dst.Dy())/2)
 
-	// Finally, draw the tile to the Result image.
-	er.RenderedLayers[len(er.RenderedLayers)-1].Image.DrawImage(tile, opt)
+	// Finally, draw the tile to the Result chunks.
+	rl.draw(tile, opt, dst)
 
 }
 
-// Render clears, and then renders out each visible Layer in an ldtgo.Level instance.
-func (er *TileRenderer) Render(level *ldtkgo.Level) {
-
+// Render clears, and then renders out each visible Layer in a MapLevel instance.
+func (er *TileRenderer) Render(level *MapLevel) {
 	er.Clear()
+	er.RenderAt(level, image.Point{})
+}
 
-	for _, layer := range level.Layers {
-
-		switch layer.Type {
-
-		case ldtkgo.LayerTypeIntGrid: // IntGrids get autotiles automatically
-			fallthrough
-		case ldtkgo.LayerTypeAutoTile:
-			fallthrough
-		case ldtkgo.LayerTypeTile:
-
-			if tiles := layer.AllTiles(); len(tiles) > 0 {
-
-				er.beginLayer(layer, level.Width, level.Height)
-
-				for _, tileData := range tiles {
-					// er.renderTile(tile.Position[0]+layer.OffsetX, tile.Position[1]+layer.OffsetY, tile.Src[0], tile.Src[1], layer.GridSize, layer.GridSize, tile.Flip)
-
-					// Subimage the Tile from the Tileset
-					tile := er.Tilesets[er.CurrentTileset].SubImage(image.Rect(tileData.Src[0], tileData.Src[1], tileData.Src[0]+layer.GridSize, tileData.Src[1]+layer.GridSize)).(*ebiten.Image)
-
-					opt := &ebiten.DrawImageOptions{}
-
-					// We have to offset the tile to be centered before flipping
-					opt.GeoM.Translate(float64(-layer.GridSize/2), float64(-layer.GridSize/2))
+// RenderAt renders out each visible Layer in a MapLevel instance on top of
+// what's already rendered, with the level at origin, e.g. for the levels next
+// to the current one in its world.
+func (er *TileRenderer) RenderAt(level *MapLevel, origin image.Point) {
 
-					// Handle flipping; first bit in byte is horizontal flipping, second is vertical flipping.
+	// In LDtk the numbering order is from top-to-bottom, but the drawing order is from bottom-to-top.
+	for i := len(level.Layers) - 1; i >= 0; i-- {
+		layer := level.Layers[i]
+		if !layer.Visible {
+			continue
+		}
 
-					if tileData.FlipX() {
-						opt.GeoM.Scale(-1, 1)
-					}
-					if tileData.FlipY() {
-						opt.GeoM.Scale(1, -1)
-					}
+		if layer.Type != MapLayerTiles || len(layer.Tiles) == 0 {
+			continue
+		}
 
-					// Undo offsetting
-					opt.GeoM.Translate(float64(layer.GridSize/2), float64(layer.GridSize/2))
+		rendered := er.beginLayer(layer, level.Width, level.Height, origin)
+		for _, tile := range layer.Tiles {
+			if tile.Tileset == nil {
+				continue
+			}
+			dst := image.Rectangle{Max: tile.Src.Size()}.Add(image.Pt(tile.Position[0], tile.Position[1]))
+			rendered.renderTile(er.tileset(tile.Tileset), tile.Src, dst, tile.FlipX, tile.FlipY)
+		}
 
-					// Move tile to final position; note that slightly unlike LDtk, layer offsets in LDtk-Go are added directly into the final tiles' X and Y positions. This means that with this renderer,
-					// if a layer's offset pushes tiles outside of the layer's render Result image, they will be cut off. On LDtk, the tiles are still rendered, of course.
-					opt.GeoM.Translate(float64(tileData.Position[0]+layer.OffsetX), float64(tileData.Position[1]+layer.OffsetY))
+	}
 
-					// Finally, draw the tile to the Result image.
-					er.RenderedLayers[len(er.RenderedLayers)-1].Image.DrawImage(tile, opt)
+}
 
-				}
+// transform returns where a point in the layer ends up in the level when the
+// camera is looking at the given point, with the layer's offset and parallax.
+// LDtk lines parallax layers up with the level when the camera is in the
+// middle of it.
+func (rl *RenderedLayer) transform(x, y, camX, camY float64) (float64, float64) {
+	cx := float64(rl.Origin.X) + float64(rl.bounds.Dx())/2
+	cy := float64(rl.Origin.Y) + float64(rl.bounds.Dy())/2
+	sx, sy := rl.scale()
+	x += float64(rl.Layer.OffsetX + rl.Origin.X)
+	y += float64(rl.Layer.OffsetY + rl.Origin.Y)
+	return (x-cx)*sx + cx + (camX-cx)*rl.Layer.ParallaxX,
+		(y-cy)*sy + cy + (camY-cy)*rl.Layer.ParallaxY
+}
 
-			}
+// scale returns how much the layer is shrunk by parallax scaling
+func (rl *RenderedLayer) scale() (float64, float64) {
+	if !rl.Layer.ParallaxScaling {
+		return 1, 1
+	}
+	return 1 - rl.Layer.ParallaxX, 1 - rl.Layer.ParallaxY
+}
 
+// visibleChunks returns the chunks of the layer that end up inside view when
+// the camera is looking at the given point
+func (rl *RenderedLayer) visibleChunks(view image.Rectangle, camX, camY float64) []*TileChunk {
+	var visible []*TileChunk
+	for _, c := range rl.Chunks {
+		x0, y0 := rl.transform(float64(c.Rect.Min.X), float64(c.Rect.Min.Y), camX, camY)
+		x1, y1 := rl.transform(float64(c.Rect.Max.X), float64(c.Rect.Max.Y), camX, camY)
+		if image.Rect(int(x0), int(y0), int(x1)+1, int(y1)+1).Overlaps(view) {
+			visible = append(visible, c)
 		}
-
 	}
+	return visible
+}
 
-	// Reverse sort the layers when drawing because in LDtk, the numbering order is from top-to-bottom, but the drawing order is from bottom-to-top.
-	sort.Slice(er.RenderedLayers, func(i, j int) bool {
-		return i > j
-	})
+// cameraView returns the part of the level that's on the screen at the
+// camera's zoom, the surface can be bigger than that after zooming out
+func cameraView(cam *camera.Camera) image.Rectangle {
+	w := int(math.Ceil(float64(cam.Width) / cam.Scale / 2))
+	h := int(math.Ceil(float64(cam.Height) / cam.Scale / 2))
+	return image.Rect(int(cam.X)-w, int(cam.Y)-h, int(cam.X)+w, int(cam.Y)+h).
+		Inset(-1) // Rounding shouldn't leave gaps
+}
 
+// Draw draws the chunks of every rendered layer that the camera can see to the
+// camera surface.
+func (er *TileRenderer) Draw(cam *camera.Camera) {
+	view := cameraView(cam)
+	for _, layer := range er.RenderedLayers {
+		sx, sy := layer.scale()
+		for _, c := range layer.visibleChunks(view, cam.X, cam.Y) {
+			x0, y0 := layer.transform(float64(c.Rect.Min.X), float64(c.Rect.Min.Y), cam.X, cam.Y)
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Scale(sx, sy)
+			op.GeoM.Translate(snap(x0), snap(y0))
+			op.GeoM.Concat(cam.GetTranslation(0, 0).GeoM)
+			op.ColorScale.ScaleAlpha(float32(layer.Layer.Opacity))
+			cam.Surface.DrawImage(c.Image, op)
+		}
+	}
 }
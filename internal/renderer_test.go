@@ -0,0 +1,129 @@
+package cr1ckt
+
+import (
+	"image"
+	"reflect"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	camera "github.com/melonfunction/ebiten-camera"
+)
+
+func TestFloorDiv(t *testing.T) {
+	cases := []struct{ a, b, want int }{
+		{0, 512, 0},
+		{511, 512, 0},
+		{512, 512, 1},
+		{1439, 512, 2},
+		{-1, 512, -1},
+		{-512, 512, -1},
+		{-513, 512, -2},
+	}
+	for _, c := range cases {
+		if got := floorDiv(c.a, c.b); got != c.want {
+			t.Errorf("floorDiv(%d, %d) is %d, want %d", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestLayerTransform(t *testing.T) {
+	cases := []struct {
+		layer    MapLayer
+		camX     float64
+		x, wantX float64
+		comment  string
+	}{
+		{MapLayer{}, 100, 50, 50, "no parallax"},
+		{MapLayer{OffsetX: 8}, 100, 50, 58, "offset"},
+		{MapLayer{ParallaxX: 0.5}, 720, 50, 50, "camera in the middle"},
+		{MapLayer{ParallaxX: 0.5}, 320, 50, -150, "camera on the left"},
+		{MapLayer{ParallaxX: 0.5, ParallaxScaling: true}, 720, 0, 360, "scaled towards the middle"},
+	}
+	for _, c := range cases {
+		c.layer.Opacity = 1
+		rl := &RenderedLayer{
+			Layer:  &c.layer,
+			bounds: image.Rect(0, 0, 1440, 1440),
+		}
+		if x, _ := rl.transform(c.x, 0, c.camX, 720); x != c.wantX {
+			t.Errorf("Layer with %s puts %v at %v, want %v", c.comment, c.x, x, c.wantX)
+		}
+	}
+}
+
+// testLoader loads a blank tileset for every path
+type testLoader struct{}
+
+func (testLoader) LoadTileset(string) *ebiten.Image {
+	return ebiten.NewImage(64, 64)
+}
+
+func TestRenderChunks(t *testing.T) {
+	tileset := &MapTileset{Path: "tileset.png"}
+	level := &MapLevel{Width: 1100, Height: 600, Layers: []*MapLayer{{
+		Type:    MapLayerTiles,
+		Visible: true,
+		Opacity: 1,
+		Tiles: []*MapTile{
+			{Position: []int{504, 0}, Src: image.Rect(0, 0, 16, 16), Tileset: tileset},
+			{Position: []int{0, 504}, Src: image.Rect(0, 0, 16, 16), Tileset: tileset},
+		},
+	}}}
+	r := NewTileRenderer(testLoader{})
+	r.Render(level)
+	if len(r.RenderedLayers) != 1 {
+		t.Fatalf("Rendered %d layers, want 1", len(r.RenderedLayers))
+	}
+	layer := r.RenderedLayers[0]
+
+	// Tiles across a boundary are drawn to the chunks on both sides, and the
+	// chunks at the edges are cut down to the level
+	var rects []image.Rectangle
+	for _, c := range layer.Chunks {
+		rects = append(rects, c.Rect)
+	}
+	want := []image.Rectangle{
+		image.Rect(0, 0, 512, 512),
+		image.Rect(512, 0, 1024, 512),
+		image.Rect(0, 512, 512, 600),
+	}
+	if !reflect.DeepEqual(rects, want) {
+		t.Fatalf("Chunks are %v, want %v", rects, want)
+	}
+
+	cases := []struct {
+		view    image.Rectangle
+		want    []*TileChunk
+		comment string
+	}{
+		{image.Rect(0, 0, 1100, 600), layer.Chunks, "whole level"},
+		{image.Rect(600, 100, 900, 300), layer.Chunks[1:2], "right of the boundary"},
+		{image.Rect(400, 400, 600, 550), layer.Chunks, "on the corner"},
+		{image.Rect(600, 520, 900, 600), nil, "where no tiles are"},
+		{image.Rect(1200, 0, 1500, 300), nil, "outside the level"},
+	}
+	for _, c := range cases {
+		if got := layer.visibleChunks(c.view, 550, 300); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Visible chunks %s are %v, want %v", c.comment, got, c.want)
+		}
+	}
+}
+
+func TestCameraView(t *testing.T) {
+	cases := []struct {
+		scale   float64
+		want    image.Rectangle
+		comment string
+	}{
+		{1, image.Rect(179, 259, 821, 741), "no zoom"},
+		{0.5, image.Rect(-141, 19, 1141, 981), "zoomed out"},
+		{2, image.Rect(339, 379, 661, 621), "zoomed in"},
+	}
+	for _, c := range cases {
+		// Only the screen size and zoom matter, not the size of the surface
+		cam := &camera.Camera{X: 500, Y: 500, Width: 640, Height: 480, Scale: c.scale}
+		if got := cameraView(cam); got != c.want {
+			t.Errorf("View %s is %v, want %v", c.comment, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2021 Siôn le Roux.  All rights reserved.
+// Use of this source code is subject to an MIT-style
+// licence which can be found in the LICENSE file.
+
+package cr1ckt
+
+// Rules are the physics and game rules in effect for a level, they start out
+// as the package defaults and any of them can be overridden by a field with
+// the same name on the level in LDtk
+type Rules struct {
+	VelocityDenominator int
+	VelocityXMultiplier int
+	MinPrime            int
+	MaxPrime            int
+	BlacknessFactor     int
+	BlacknessMode       string
+	BlacknessSpace      string
+	ChirpUses           int
+	Gravity             int  // Percentage of normal gravity
+	WaterDeadly         bool // Whether falling in water kills the cricket
+}
+
+// DefaultRules returns the rules from the package defaults, which may have been
+// changed by the config file
+func DefaultRules() Rules {
+	return Rules{
+		VelocityDenominator: VelocityDenominator,
+		VelocityXMultiplier: VelocityXMultiplier,
+		MinPrime:            MinPrime,
+		MaxPrime:            MaxPrime,
+		BlacknessFactor:     BlacknessFactor,
+		BlacknessMode:       BlacknessMode,
+		BlacknessSpace:      BlacknessSpace,
+		ChirpUses:           ChirpUses,
+		Gravity:             Gravity,
+		WaterDeadly:         true,
+	}
+}
+
+// LevelRules returns the default rules with any overrides from the fields of
+// the given level applied
+func LevelRules(level *MapLevel) Rules {
+	r := DefaultRules()
+	ints := map[string]*int{
+		"VelocityDenominator": &r.VelocityDenominator,
+		"VelocityXMultiplier": &r.VelocityXMultiplier,
+		"MinPrime":            &r.MinPrime,
+		"MaxPrime":            &r.MaxPrime,
+		"BlacknessFactor":     &r.BlacknessFactor,
+		"ChirpUses":           &r.ChirpUses,
+		"Gravity":             &r.Gravity,
+	}
+	for name, v := range ints {
+		if p := level.PropertyByIdentifier(name); p != nil && !p.IsNull() {
+			*v = p.AsInt()
+		}
+	}
+	if p := level.PropertyByIdentifier("BlacknessMode"); p != nil && !p.IsNull() {
+		r.BlacknessMode = p.AsString()
+	}
+	if p := level.PropertyByIdentifier("BlacknessSpace"); p != nil && !p.IsNull() {
+		r.BlacknessSpace = p.AsString()
+	}
+	if p := level.PropertyByIdentifier("WaterDeadly"); p != nil && !p.IsNull() {
+		r.WaterDeadly = p.AsBool()
+	}
+	// Dividing by zero would crash the game
+	if r.VelocityDenominator < 1 {
+		r.VelocityDenominator = 1
+	}
+	if r.BlacknessFactor < 1 {
+		r.BlacknessFactor = 1
+	}
+	if r.MaxPrime < 1 {
+		r.MaxPrime = 1
+	}
+	if r.MinPrime > r.MaxPrime {
+		r.MinPrime = r.MaxPrime
+	}
+	return r
+}
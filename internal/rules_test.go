@@ -0,0 +1,48 @@
+package cr1ckt
+
+import (
+	"testing"
+)
+
+func TestLevelRules(t *testing.T) {
+	plain := &MapLevel{}
+	if r := LevelRules(plain); r != DefaultRules() {
+		t.Errorf("Level without fields has rules %+v, want defaults %+v", r, DefaultRules())
+	}
+
+	moon := &MapLevel{Properties: Properties{
+		{Identifier: "Gravity", Value: 25.0},
+		{Identifier: "MaxPrime", Value: 3.0},
+		{Identifier: "WaterDeadly", Value: false},
+		{Identifier: "BlacknessFactor", Value: nil},
+	}}
+	r := LevelRules(moon)
+	if r.Gravity != 25 {
+		t.Errorf("Gravity is %d, want 25", r.Gravity)
+	}
+	if r.MaxPrime != 3 {
+		t.Errorf("MaxPrime is %d, want 3", r.MaxPrime)
+	}
+	if r.WaterDeadly {
+		t.Error("Water should not be deadly")
+	}
+	if r.BlacknessFactor != BlacknessFactor {
+		t.Errorf("Null BlacknessFactor is %d, want default %d", r.BlacknessFactor, BlacknessFactor)
+	}
+
+	broken := &MapLevel{Properties: Properties{
+		{Identifier: "VelocityDenominator", Value: 0.0},
+		{Identifier: "MaxPrime", Value: 0.0},
+		{Identifier: "MinPrime", Value: 4.0},
+	}}
+	r = LevelRules(broken)
+	if r.VelocityDenominator < 1 {
+		t.Errorf("VelocityDenominator is %d, it shouldn't be allowed below 1", r.VelocityDenominator)
+	}
+	if r.MaxPrime < 1 {
+		t.Errorf("MaxPrime is %d, it shouldn't be allowed below 1", r.MaxPrime)
+	}
+	if r.MinPrime > r.MaxPrime {
+		t.Errorf("MinPrime is %d, it shouldn't be allowed above MaxPrime %d", r.MinPrime, r.MaxPrime)
+	}
+}
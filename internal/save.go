@@ -0,0 +1,55 @@
+// Copyright 2021 Siôn le Roux.  All rights reserved.
+// Use of this source code is subject to an MIT-style
+// licence which can be found in the LICENSE file.
+
+package cr1ckt
+
+import (
+	"log"
+	"strconv"
+
+	"gopkg.in/ini.v1"
+)
+
+// SaveFile is the file name progress is saved to, next to the game binary
+const SaveFile = "cr1ckt.sav"
+
+// Save is the player's progress that is kept between play sessions
+type Save struct {
+	// Seeds is the most seeds collected in each level, by level identifier
+	Seeds map[string]int
+}
+
+// LoadSave reads saved progress from a file, if there isn't one yet you just
+// get an empty save
+func LoadSave(name string) *Save {
+	save := &Save{Seeds: make(map[string]int)}
+	cfg, err := ini.Load(name)
+	if err != nil {
+		log.Println("No saved progress loaded:", err)
+		return save
+	}
+	for _, k := range cfg.Section("Seeds").Keys() {
+		save.Seeds[k.Name()], _ = k.Int()
+	}
+	return save
+}
+
+// RecordSeeds stores the number of seeds collected in a level if it's better
+// than the previous best, it returns whether it was a new best
+func (s *Save) RecordSeeds(level string, seeds int) bool {
+	if best, ok := s.Seeds[level]; ok && best >= seeds {
+		return false
+	}
+	s.Seeds[level] = seeds
+	return true
+}
+
+// Write writes the progress to a file, overwriting what was there before
+func (s *Save) Write(name string) error {
+	cfg := ini.Empty()
+	for level, seeds := range s.Seeds {
+		cfg.Section("Seeds").Key(level).SetValue(strconv.Itoa(seeds))
+	}
+	return cfg.SaveTo(name)
+}
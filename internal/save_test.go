@@ -0,0 +1,47 @@
+package cr1ckt
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRecordSeeds(t *testing.T) {
+	save := &Save{Seeds: map[string]int{"Level_0": 3}}
+	cases := []struct {
+		level   string
+		seeds   int
+		want    bool
+		best    int
+		comment string
+	}{
+		{"Level_0", 2, false, 3, "fewer seeds"},
+		{"Level_0", 3, false, 3, "the same seeds"},
+		{"Level_0", 5, true, 5, "more seeds"},
+		{"Level_1", 0, true, 0, "no seeds in a new level"},
+		{"Level_1", 1, true, 1, "a seed after none"},
+	}
+	for _, c := range cases {
+		if res := save.RecordSeeds(c.level, c.seeds); res != c.want {
+			t.Errorf("Recording %s is %v, want %v", c.comment, res, c.want)
+		}
+		if best := save.Seeds[c.level]; best != c.best {
+			t.Errorf("Best after %s is %d, want %d", c.comment, best, c.best)
+		}
+	}
+}
+
+func TestLoadSave(t *testing.T) {
+	name := filepath.Join(t.TempDir(), SaveFile)
+	if save := LoadSave(name); len(save.Seeds) != 0 {
+		t.Errorf("Missing save has seeds %v, want none", save.Seeds)
+	}
+
+	want := map[string]int{"Level_0": 5, "Level_1": 0, "Meadow": 12}
+	if err := (&Save{Seeds: want}).Write(name); err != nil {
+		t.Fatal(err)
+	}
+	if save := LoadSave(name); !reflect.DeepEqual(save.Seeds, want) {
+		t.Errorf("Loaded seeds are %v, want %v", save.Seeds, want)
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2021 Siôn le Roux.  All rights reserved.
+// Use of this source code is subject to an MIT-style
+// licence which can be found in the LICENSE file.
+
+package cr1ckt
+
+import (
+	"image"
+	"log"
+)
+
+func init() {
+	RegisterEntity("Seed", func(g *Game, e *MapEntity) Entity {
+		return NewSeed(e, g.seedImg.Bounds().Size())
+	})
+}
+
+// Seed is a small collectible placed in a level with a "Seed" entity in LDtk,
+// the cricket collects it by touching it
+type Seed struct {
+	Position  image.Point
+	Size      image.Point
+	Collected bool
+}
+
+// NewSeed returns a new Seed of the given size at the position of the given
+// LDtk entity
+func NewSeed(e *MapEntity, size image.Point) *Seed {
+	return &Seed{Position: entityRect(e).Min, Size: size}
+}
+
+// Hitbox returns a correctly positioned rectangular hitbox for collision
+// detection with the Seed
+func (s *Seed) Hitbox() image.Rectangle {
+	return image.Rectangle{Max: s.Size}.Add(s.Position)
+}
+
+// Touch collects the seed
+func (s *Seed) Touch(g *Game) {
+	if !s.Collected {
+		log.Println("Collected a seed")
+		s.Collected = true
+	}
+}
+
+// Draw draws the seed to the camera surface until it's collected
+func (s *Seed) Draw(g *Game) {
+	if !s.Collected {
+		g.cam.Surface.DrawImage(g.seedImg, g.cam.GetTranslation(
+			float64(s.Position.X), float64(s.Position.Y),
+		))
+	}
+}
+
+// Seeds is all the seeds in a level
+type Seeds []*Seed
+
+// Collected returns how many of the seeds have been collected so far
+func (ss Seeds) Collected() int {
+	var n int
+	for _, s := range ss {
+		if s.Collected {
+			n++
+		}
+	}
+	return n
+}
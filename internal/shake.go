@@ -0,0 +1,97 @@
+// Copyright 2021 Siôn le Roux.  All rights reserved.
+// Use of this source code is subject to an MIT-style
+// licence which can be found in the LICENSE file.
+
+package cr1ckt
+
+import (
+	"math"
+)
+
+// shakeMaxOffset is how far the camera moves at full trauma, in pixels
+const shakeMaxOffset = 8
+
+// shakeDecay is how much trauma wears off every tick
+const shakeDecay = 0.025
+
+// Shake is the screen shake and hit-stop on top of wherever the camera is
+// looking, big impacts add trauma which wears off over time
+type Shake struct {
+	Trauma float64 // From 0 to 1, the shake is trauma squared
+	Stop   int     // Ticks left of hit-stop
+	tick   int
+}
+
+// Add adds trauma to the shake, up to the maximum of 1
+func (s *Shake) Add(trauma float64) {
+	s.Trauma = math.Min(s.Trauma+trauma, 1)
+}
+
+// Freeze starts a hit-stop of the given number of ticks, unless a longer one
+// is already going
+func (s *Shake) Freeze(ticks int) {
+	if HitStop && ticks > s.Stop {
+		s.Stop = ticks
+	}
+}
+
+// Update wears the trauma off and counts down the hit-stop, it returns whether
+// the game should stay frozen this tick
+func (s *Shake) Update() bool {
+	s.tick++
+	s.Trauma = math.Max(s.Trauma-shakeDecay, 0)
+	if s.Stop > 0 {
+		s.Stop--
+		return true
+	}
+	return false
+}
+
+// Offset returns how far to move the camera this tick, a few sine waves at odd
+// frequencies make it wobble about without looking too regular
+func (s *Shake) Offset() (float64, float64) {
+	amount := ScreenShake * shakeMaxOffset * s.Trauma * s.Trauma
+	if amount == 0 {
+		return 0, 0
+	}
+	t := float64(s.tick)
+	x := math.Sin(t*1.9) * math.Cos(t*0.7)
+	y := math.Sin(t*2.3+1) * math.Cos(t*1.1)
+	return x * amount, y * amount
+}
+
+// Reset stops any shake and hit-stop, e.g. when the level changes
+func (s *Shake) Reset() {
+	s.Trauma, s.Stop = 0, 0
+}
+
+// hardFall is how far in pixels the cricket has to fall for its landing to
+// shake the screen, the highest jump goes up less than 150 pixels so ordinary
+// jumps don't shake anything and only long drops do
+const hardFall = 160
+
+// landing shakes the screen for the cricket landing after falling the given
+// number of pixels from the top of its jump, the longest falls freeze the game
+// for a moment too
+func (g *Game) landing(fall int) {
+	// Falls hit as hard as falls that much further in normal gravity, so
+	// the higher jumps in low gravity don't shake it more
+	fall = fall * g.rules.Gravity / 100
+	switch {
+	case fall >= hardFall*3:
+		g.view.Shake.Add(0.4)
+		g.view.Shake.Freeze(3)
+	case fall >= hardFall*2:
+		g.view.Shake.Add(0.25)
+	case fall >= hardFall:
+		g.view.Shake.Add(0.1)
+	}
+}
+
+// bump shakes the screen a little for the cricket bumping its head on a
+// ceiling at the given speed in pixels per tick
+func (g *Game) bump(speed int) {
+	if speed >= 4 {
+		g.view.Shake.Add(0.1)
+	}
+}
@@ -0,0 +1,59 @@
+package cr1ckt
+
+import "testing"
+
+func TestShake(t *testing.T) {
+	var s Shake
+	s.Add(0.7)
+	s.Add(0.7)
+	if s.Trauma != 1 {
+		t.Errorf("Trauma is %v, want it capped at 1", s.Trauma)
+	}
+	for i := 0; i < 100; i++ {
+		s.Update()
+	}
+	if x, y := s.Offset(); s.Trauma != 0 || x != 0 || y != 0 {
+		t.Errorf("Trauma is %v with offset (%v, %v), want it all worn off", s.Trauma, x, y)
+	}
+
+	s.Freeze(3)
+	s.Freeze(1)
+	for i := 0; i < 3; i++ {
+		if !s.Update() {
+			t.Errorf("Not frozen on tick %d of hit-stop", i)
+		}
+	}
+	if s.Update() {
+		t.Error("Still frozen after hit-stop ended")
+	}
+
+	ScreenShake = 0
+	defer func() { ScreenShake = 1 }()
+	s.Add(1)
+	if x, y := s.Offset(); x != 0 || y != 0 {
+		t.Errorf("Offset is (%v, %v) with screen shake off, want none", x, y)
+	}
+}
+
+func TestLanding(t *testing.T) {
+	cases := []struct {
+		fall       int
+		wantTrauma float64
+		wantStop   int
+		comment    string
+	}{
+		{0, 0, 0, "landing from a hop"},
+		{150, 0, 0, "landing from the highest jump"},
+		{hardFall, 0.1, 0, "hard landing"},
+		{hardFall * 2, 0.25, 0, "harder landing"},
+		{hardFall * 3, 0.4, 3, "hardest landing"},
+	}
+	for _, c := range cases {
+		g := &Game{view: NewCameraController(), rules: DefaultRules()}
+		g.landing(c.fall)
+		if s := g.view.Shake; s.Trauma != c.wantTrauma || s.Stop != c.wantStop {
+			t.Errorf("%s adds trauma %v and hit-stop %d, want %v and %d",
+				c.comment, s.Trauma, s.Stop, c.wantTrauma, c.wantStop)
+		}
+	}
+}
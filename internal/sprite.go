@@ -0,0 +1,104 @@
+// Copyright 2021 Siôn le Roux.  All rights reserved.
+// Use of this source code is subject to an MIT-style
+// licence which can be found in the LICENSE file.
+
+package cr1ckt
+
+import (
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// SpriteFrameTicks is how long each frame of an animated sprite is shown for
+// when the entity doesn't have a FrameTicks field
+const SpriteFrameTicks = 10
+
+// Sprite is an entity drawn with its tile from the map, or with the tiles in
+// its Frames field one after another if it has one
+type Sprite struct {
+	Entity     *MapEntity
+	Rect       image.Rectangle // Where it's drawn in the level, move it to move the sprite
+	Hidden     bool
+	Opacity    float64
+	Frames     []*EntityTile
+	FrameTicks int
+	tick       int
+	tilesets   []*ebiten.Image // For each frame
+	layer      *RenderedLayer  // For the entity layer's offset and parallax
+}
+
+// NewSprite returns a new Sprite for the given entity on the given layer of the
+// current level, or nil if it has no tile to draw
+func (g *Game) NewSprite(e *MapEntity, layer *MapLayer) *Sprite {
+	var frames []*EntityTile
+	if p := e.PropertyByIdentifier("Frames"); p != nil {
+		frames = p.AsTiles()
+	}
+	if len(frames) == 0 && e.Tile != nil && e.Tile.Tileset != nil {
+		frames = []*EntityTile{e.Tile}
+	}
+	if len(frames) == 0 {
+		return nil
+	}
+
+	s := &Sprite{
+		Entity:     e,
+		Rect:       entityRect(e),
+		Opacity:    layer.Opacity,
+		Frames:     frames,
+		FrameTicks: SpriteFrameTicks,
+		layer: &RenderedLayer{
+			Layer:  layer,
+			bounds: image.Rect(0, 0, g.Levels[g.Level].Width, g.Levels[g.Level].Height),
+		},
+	}
+	if p := e.PropertyByIdentifier("FrameTicks"); p != nil && !p.IsNull() && p.AsInt() > 0 {
+		s.FrameTicks = p.AsInt()
+	}
+	for _, f := range frames {
+		s.tilesets = append(s.tilesets, g.TileRenderer.tileset(f.Tileset))
+	}
+	return s
+}
+
+// Frame returns which of its frames the sprite is showing
+func (s *Sprite) Frame() int {
+	return s.tick / s.FrameTicks % len(s.Frames)
+}
+
+// Hitbox returns where the sprite is drawn in the level
+func (s *Sprite) Hitbox() image.Rectangle {
+	return s.Rect
+}
+
+// Update moves the sprite's animation along
+func (s *Sprite) Update(g *Game) {
+	s.tick++
+}
+
+// Draw draws the sprite's current frame to the camera surface, stretched to
+// the size of the entity and moved like the rest of its layer, if the camera
+// can see it
+func (s *Sprite) Draw(g *Game) {
+	if s.Hidden {
+		return
+	}
+	x0, y0 := s.layer.transform(float64(s.Rect.Min.X), float64(s.Rect.Min.Y), g.cam.X, g.cam.Y)
+	x1, y1 := s.layer.transform(float64(s.Rect.Max.X), float64(s.Rect.Max.Y), g.cam.X, g.cam.Y)
+	if !image.Rect(int(x0), int(y0), int(x1)+1, int(y1)+1).Overlaps(cameraView(g.cam)) {
+		return
+	}
+	i := s.Frame()
+	src := s.Frames[i].Src
+	sx, sy := s.layer.scale()
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(
+		float64(s.Rect.Dx())/float64(src.Dx())*sx,
+		float64(s.Rect.Dy())/float64(src.Dy())*sy,
+	)
+	op.GeoM.Translate(snap(x0), snap(y0))
+	op.GeoM.Concat(g.cam.GetTranslation(0, 0).GeoM)
+	op.ColorScale.ScaleAlpha(float32(s.Opacity))
+	g.cam.Surface.DrawImage(s.tilesets[i].SubImage(src).(*ebiten.Image), op)
+}
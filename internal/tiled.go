@@ -0,0 +1,517 @@
+// Copyright 2021 Siôn le Roux.  All rights reserved.
+// Use of this source code is subject to an MIT-style
+// licence which can be found in the LICENSE file.
+
+package cr1ckt
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Flags in the top bits of a Tiled tile's global ID
+const (
+	tiledFlipX        = 0x80000000
+	tiledFlipY        = 0x40000000
+	tiledFlipDiagonal = 0x20000000 // Set for rotated tiles, which aren't supported
+	tiledFlipMask     = 0xf0000000 // All the flags, including rotation
+)
+
+// TiledSource is a MapSource for one Tiled map, saved as XML (.tmx) or JSON
+// (.tmj), which is one level
+type TiledSource struct {
+	Name  string // Of the map file, in the embedded FS
+	level *MapLevel
+}
+
+// tiledMap is a Tiled map as it's saved in either format
+type tiledMap struct {
+	Orientation     string          `xml:"orientation,attr" json:"orientation"`
+	Width           int             `xml:"width,attr" json:"width"` // In tiles
+	Height          int             `xml:"height,attr" json:"height"`
+	TileWidth       int             `xml:"tilewidth,attr" json:"tilewidth"`
+	TileHeight      int             `xml:"tileheight,attr" json:"tileheight"`
+	Infinite        tiledBool       `xml:"infinite,attr" json:"infinite"`
+	BackgroundColor string          `xml:"backgroundcolor,attr" json:"backgroundcolor"`
+	Properties      []tiledProperty `xml:"properties>property" json:"properties"`
+	Tilesets        []*tiledTileset `xml:"tileset" json:"tilesets"`
+	Layers          []*tiledLayer   `xml:",any" json:"layers"` // Bottom first
+}
+
+// tiledTileset is a tileset in a Tiled map, or in its own file if it has a
+// source
+type tiledTileset struct {
+	FirstGID   uint32 `xml:"firstgid,attr" json:"firstgid"`
+	Source     string `xml:"source,attr" json:"source"`
+	TileWidth  int    `xml:"tilewidth,attr" json:"tilewidth"`
+	TileHeight int    `xml:"tileheight,attr" json:"tileheight"`
+	Spacing    int    `xml:"spacing,attr" json:"spacing"`
+	Margin     int    `xml:"margin,attr" json:"margin"`
+	Columns    int    `xml:"columns,attr" json:"columns"`
+	Image      string `xml:"-" json:"image"`
+	ImageXML   struct {
+		Source string `xml:"source,attr"`
+	} `xml:"image" json:"-"`
+	Tiles []struct {
+		ID        int `xml:"id,attr" json:"id"`
+		Animation []struct {
+			TileID   int `xml:"tileid,attr" json:"tileid"`
+			Duration int `xml:"duration,attr" json:"duration"` // In milliseconds
+		} `xml:"animation>frame" json:"animation"`
+	} `xml:"tile" json:"tiles"`
+	tileset *MapTileset
+}
+
+// tiledLayer is any kind of layer in a Tiled map
+type tiledLayer struct {
+	XMLName     xml.Name
+	Type        string          `xml:"-" json:"type"` // tilelayer, objectgroup, group or imagelayer
+	Name        string          `xml:"name,attr" json:"name"`
+	Width       int             `xml:"width,attr" json:"width"`
+	Opacity     *float64        `xml:"opacity,attr" json:"opacity"`
+	Visible     *tiledBool      `xml:"visible,attr" json:"visible"`
+	OffsetX     float64         `xml:"offsetx,attr" json:"offsetx"`
+	OffsetY     float64         `xml:"offsety,attr" json:"offsety"`
+	ParallaxX   *float64        `xml:"parallaxx,attr" json:"parallaxx"`
+	ParallaxY   *float64        `xml:"parallaxy,attr" json:"parallaxy"`
+	Properties  []tiledProperty `xml:"properties>property" json:"properties"`
+	DataXML     *tiledData      `xml:"data" json:"-"`
+	Data        json.RawMessage `xml:"-" json:"data"` // An array of IDs or a string
+	Encoding    string          `xml:"-" json:"encoding"`
+	Compression string          `xml:"-" json:"compression"`
+	Objects     []*tiledObject  `xml:"object" json:"objects"`
+	Layers      []*tiledLayer   `xml:",any" json:"layers"` // In groups
+}
+
+// tiledData is the tiles of a layer in a .tmx file
+type tiledData struct {
+	Encoding    string `xml:"encoding,attr"`
+	Compression string `xml:"compression,attr"`
+	Text        string `xml:",chardata"`
+	Tiles       []struct {
+		GID uint32 `xml:"gid,attr"`
+	} `xml:"tile"`
+}
+
+// tiledObject is an object on an object layer
+type tiledObject struct {
+	ID         int             `xml:"id,attr" json:"id"`
+	Name       string          `xml:"name,attr" json:"name"`
+	Type       string          `xml:"type,attr" json:"type"`
+	Class      string          `xml:"class,attr" json:"class"` // What Type is called in Tiled 1.9
+	X          float64         `xml:"x,attr" json:"x"`
+	Y          float64         `xml:"y,attr" json:"y"`
+	Width      float64         `xml:"width,attr" json:"width"`
+	Height     float64         `xml:"height,attr" json:"height"`
+	GID        uint32          `xml:"gid,attr" json:"gid"` // Set for tile objects
+	Properties []tiledProperty `xml:"properties>property" json:"properties"`
+}
+
+// tiledProperty is a custom property, its value is a string in .tmx files and
+// whatever type it is in .tmj files
+type tiledProperty struct {
+	Name     string      `xml:"name,attr" json:"name"`
+	Type     string      `xml:"type,attr" json:"type"`
+	Value    interface{} `xml:"-" json:"value"`
+	ValueXML *string     `xml:"value,attr" json:"-"`
+	Text     string      `xml:",chardata" json:"-"` // Multi-line strings
+}
+
+// tiledBool is a bool that's 0 or 1 in .tmx files and true or false in .tmj
+// files
+type tiledBool bool
+
+// UnmarshalXMLAttr reads a 0 or 1
+func (b *tiledBool) UnmarshalXMLAttr(attr xml.Attr) error {
+	*b = attr.Value == "1" || attr.Value == "true"
+	return nil
+}
+
+// UnmarshalJSON reads a true or false
+func (b *tiledBool) UnmarshalJSON(data []byte) error {
+	*b = string(data) == "true" || string(data) == "1"
+	return nil
+}
+
+// ReadTiled reads a Tiled map, tilesets saved in their own files are read with
+// readFile
+func ReadTiled(name string, data []byte, readFile func(string) ([]byte, error)) (*TiledSource, error) {
+	m := &tiledMap{}
+	if err := tiledUnmarshal(name, data, m); err != nil {
+		return nil, err
+	}
+	if m.Orientation != "" && m.Orientation != "orthogonal" {
+		return nil, fmt.Errorf("%s maps aren't supported, only orthogonal ones", m.Orientation)
+	}
+	if m.Infinite {
+		return nil, fmt.Errorf("infinite maps aren't supported, untick Infinite in the map properties")
+	}
+	if m.TileWidth != m.TileHeight {
+		return nil, fmt.Errorf("%dx%d tiles aren't supported, the map's tiles have to be square", m.TileWidth, m.TileHeight)
+	}
+
+	dir := path.Dir(name)
+	for i, ts := range m.Tilesets {
+		imageDir := dir
+		if ts.Source != "" {
+			// A tileset in its own file, its image is relative to that file
+			source := path.Join(dir, ts.Source)
+			data, err := readFile(source)
+			if err != nil {
+				return nil, err
+			}
+			external := &tiledTileset{}
+			if err := tiledUnmarshal(source, data, external); err != nil {
+				return nil, err
+			}
+			external.FirstGID = ts.FirstGID
+			ts, m.Tilesets[i] = external, external
+			imageDir = path.Dir(source)
+		}
+		imageFile := ts.Image
+		if imageFile == "" {
+			imageFile = ts.ImageXML.Source
+		}
+		if ts.Columns == 0 {
+			return nil, fmt.Errorf("tilesets that are collections of images aren't supported")
+		}
+		ts.tileset = &MapTileset{Path: assetsPath(path.Join(imageDir, imageFile))}
+	}
+
+	level := &MapLevel{
+		Identifier: strings.TrimSuffix(path.Base(name), path.Ext(name)),
+		World:      name,
+		Width:      m.Width * m.TileWidth,
+		Height:     m.Height * m.TileHeight,
+		BGColor:    tiledColor(m.BackgroundColor),
+		Loaded:     true,
+		Properties: tiledProperties(m.Properties),
+	}
+	var err error
+	root := tiledGroup{Opacity: 1, ParallaxX: 1, ParallaxY: 1, Visible: true}
+	if level.Layers, err = m.layers(m.Layers, root); err != nil {
+		return nil, err
+	}
+	return &TiledSource{Name: name, level: level}, nil
+}
+
+// Levels returns the map's level
+func (s *TiledSource) Levels() []*MapLevel {
+	return []*MapLevel{s.level}
+}
+
+// LoadLevel does nothing because a map is read all at once
+func (s *TiledSource) LoadLevel(level *MapLevel) error {
+	return nil
+}
+
+// tiledUnmarshal reads a Tiled file as JSON or XML depending on its extension
+func tiledUnmarshal(name string, data []byte, v interface{}) error {
+	switch path.Ext(name) {
+	case ".tmj", ".tsj", ".json":
+		return json.Unmarshal(data, v)
+	}
+	return xml.Unmarshal(data, v)
+}
+
+// tiledGroup is the settings of the groups a layer is in, which are added on
+// to the layer's own
+type tiledGroup struct {
+	OffsetX, OffsetY     float64
+	Opacity              float64
+	ParallaxX, ParallaxY float64
+	Visible              bool
+}
+
+// layers turns Tiled layers into level layers, on top first, with groups
+// flattened so the layers in them have the group's settings added on
+func (m *tiledMap) layers(layers []*tiledLayer, group tiledGroup) ([]*MapLayer, error) {
+	var result []*MapLayer
+	for _, l := range layers {
+		// In .tmx files the type is which element it is
+		switch l.XMLName.Local {
+		case "layer":
+			l.Type = "tilelayer"
+		case "objectgroup", "group", "imagelayer":
+			l.Type = l.XMLName.Local
+		}
+
+		settings := group
+		settings.OffsetX += l.OffsetX
+		settings.OffsetY += l.OffsetY
+		if l.Opacity != nil {
+			settings.Opacity *= *l.Opacity
+		}
+		if l.ParallaxX != nil {
+			settings.ParallaxX *= *l.ParallaxX
+		}
+		if l.ParallaxY != nil {
+			settings.ParallaxY *= *l.ParallaxY
+		}
+		if l.Visible != nil && !*l.Visible {
+			settings.Visible = false
+		}
+
+		switch l.Type {
+		case "group":
+			children, err := m.layers(l.Layers, settings)
+			if err != nil {
+				return nil, err
+			}
+			result = append(children, result...)
+			continue
+		case "tilelayer", "objectgroup":
+		default:
+			continue
+		}
+
+		layer := &MapLayer{
+			Identifier: l.Name,
+			Type:       MapLayerTiles,
+			GridSize:   m.TileWidth,
+			OffsetX:    int(settings.OffsetX),
+			OffsetY:    int(settings.OffsetY),
+			Visible:    settings.Visible,
+			Opacity:    settings.Opacity,
+			// Tiled's parallax factor is how much the layer moves with the
+			// camera, LDtk's is how much less it moves
+			ParallaxX: 1 - settings.ParallaxX,
+			ParallaxY: 1 - settings.ParallaxY,
+		}
+		if l.Type == "objectgroup" {
+			layer.Type = MapLayerEntities
+			for _, o := range l.Objects {
+				layer.Entities = append(layer.Entities, m.entity(o))
+			}
+		} else {
+			gids, err := l.gids()
+			if err != nil {
+				return nil, fmt.Errorf("layer %s: %v", l.Name, err)
+			}
+			for i, gid := range gids {
+				if gid&tiledFlipDiagonal != 0 {
+					return nil, fmt.Errorf("layer %s: rotated tiles aren't supported, only flipped ones", l.Name)
+				}
+				tile := m.tile(gid)
+				if tile == nil {
+					continue
+				}
+				x, y := i%l.Width*m.TileWidth, i/l.Width*m.TileHeight
+				// Tiles bigger than the grid stick up out of their cell
+				tile.Position = []int{x, y + m.TileHeight - tile.Src.Dy()}
+				layer.Tiles = append(layer.Tiles, tile)
+			}
+		}
+		// Tiled lists layers bottom first
+		result = append([]*MapLayer{layer}, result...)
+	}
+	return result, nil
+}
+
+// gids returns the global tile IDs of a tile layer, one for each cell
+func (l *tiledLayer) gids() ([]uint32, error) {
+	encoding, compression, text := l.Encoding, l.Compression, ""
+	if l.DataXML != nil {
+		encoding, compression, text = l.DataXML.Encoding, l.DataXML.Compression, l.DataXML.Text
+		if encoding == "" {
+			var gids []uint32
+			for _, t := range l.DataXML.Tiles {
+				gids = append(gids, t.GID)
+			}
+			return gids, nil
+		}
+	} else if len(l.Data) > 0 && l.Data[0] == '[' {
+		var gids []uint32
+		err := json.Unmarshal(l.Data, &gids)
+		return gids, err
+	} else if err := json.Unmarshal(l.Data, &text); err != nil {
+		return nil, err
+	}
+
+	switch encoding {
+	case "csv":
+		var gids []uint32
+		for _, field := range strings.Split(text, ",") {
+			if field = strings.TrimSpace(field); field == "" {
+				continue
+			}
+			gid, err := strconv.ParseUint(field, 10, 32)
+			if err != nil {
+				return nil, err
+			}
+			gids = append(gids, uint32(gid))
+		}
+		return gids, nil
+	case "base64":
+	default:
+		return nil, fmt.Errorf("unknown tile data encoding %q", encoding)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(text))
+	if err != nil {
+		return nil, err
+	}
+	var r io.Reader = bytes.NewReader(data)
+	switch compression {
+	case "":
+	case "zlib":
+		if r, err = zlib.NewReader(r); err != nil {
+			return nil, err
+		}
+	case "gzip":
+		if r, err = gzip.NewReader(r); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%s compression isn't supported, use zlib, gzip or CSV", compression)
+	}
+	if data, err = io.ReadAll(r); err != nil {
+		return nil, err
+	}
+	gids := make([]uint32, len(data)/4)
+	for i := range gids {
+		gids[i] = binary.LittleEndian.Uint32(data[i*4:])
+	}
+	return gids, nil
+}
+
+// tileset returns the tileset a global tile ID is in, and the tile's ID in
+// that tileset
+func (m *tiledMap) tileset(gid uint32) (*tiledTileset, int) {
+	id := gid &^ tiledFlipMask
+	var found *tiledTileset
+	for _, ts := range m.Tilesets {
+		if ts.FirstGID <= id && (found == nil || ts.FirstGID > found.FirstGID) {
+			found = ts
+		}
+	}
+	if id == 0 || found == nil {
+		return nil, 0
+	}
+	return found, int(id - found.FirstGID)
+}
+
+// src returns the part of the tileset image a tile is
+func (ts *tiledTileset) src(id int) image.Rectangle {
+	x := ts.Margin + id%ts.Columns*(ts.TileWidth+ts.Spacing)
+	y := ts.Margin + id/ts.Columns*(ts.TileHeight+ts.Spacing)
+	return image.Rect(x, y, x+ts.TileWidth, y+ts.TileHeight)
+}
+
+// tile returns the tile for a global tile ID, or nil if it's empty
+func (m *tiledMap) tile(gid uint32) *MapTile {
+	ts, id := m.tileset(gid)
+	if ts == nil {
+		return nil
+	}
+	return &MapTile{
+		ID:      id,
+		Src:     ts.src(id),
+		Tileset: ts.tileset,
+		FlipX:   gid&tiledFlipX != 0,
+		FlipY:   gid&tiledFlipY != 0,
+	}
+}
+
+// entity turns a Tiled object into an entity, its class is its identifier
+func (m *tiledMap) entity(o *tiledObject) *MapEntity {
+	e := &MapEntity{
+		Identifier: o.Type,
+		Iid:        strconv.Itoa(o.ID),
+		Position:   []int{int(o.X), int(o.Y)},
+		Width:      int(o.Width),
+		Height:     int(o.Height),
+		Properties: tiledProperties(o.Properties),
+	}
+	if e.Identifier == "" {
+		e.Identifier = o.Class
+	}
+	if e.Identifier == "" {
+		e.Identifier = o.Name
+	}
+
+	ts, id := m.tileset(o.GID)
+	if ts == nil {
+		return e
+	}
+	// Tile objects are positioned by their bottom left corner
+	e.Position[1] -= e.Height
+	e.Tile = &EntityTile{Tileset: ts.tileset, Src: ts.src(id)}
+	// Animated tiles become Frames like in LDtk
+	for _, t := range ts.Tiles {
+		if t.ID != id || len(t.Animation) == 0 || e.PropertyByIdentifier("Frames") != nil {
+			continue
+		}
+		var frames []interface{}
+		for _, f := range t.Animation {
+			frames = append(frames, &EntityTile{Tileset: ts.tileset, Src: ts.src(f.TileID)})
+		}
+		e.Properties = append(e.Properties, &Property{Identifier: "Frames", Value: frames})
+		if e.PropertyByIdentifier("FrameTicks") == nil {
+			// Durations are in milliseconds and there are 60 ticks a second
+			ticks := math.Max(float64(t.Animation[0].Duration*60/1000), 1)
+			e.Properties = append(e.Properties, &Property{Identifier: "FrameTicks", Value: ticks})
+		}
+	}
+	return e
+}
+
+// tiledProperties turns Tiled custom properties into properties, numbers are
+// float64s like in LDtk and object properties are entity references
+func tiledProperties(props []tiledProperty) Properties {
+	var ps Properties
+	for _, p := range props {
+		value := p.Value
+		if p.ValueXML != nil {
+			value = *p.ValueXML
+		} else if value == nil {
+			value = p.Text
+		}
+		if s, ok := value.(string); ok {
+			switch p.Type {
+			case "int", "float", "object":
+				value, _ = strconv.ParseFloat(s, 64)
+			case "bool":
+				value = s == "true"
+			}
+		}
+		switch p.Type {
+		case "object":
+			id, _ := value.(float64)
+			if value = nil; id != 0 {
+				value = EntityRef(strconv.Itoa(int(id)))
+			}
+		case "class":
+			value = nil
+		}
+		ps = append(ps, &Property{Identifier: p.Name, Value: value})
+	}
+	return ps
+}
+
+// tiledColor reads a Tiled colour, which is #RRGGBB or #AARRGGBB
+func tiledColor(s string) color.Color {
+	s = strings.TrimPrefix(s, "#")
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return color.RGBA{}
+	}
+	c := color.NRGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}
+	if len(s) == 8 {
+		c.A = uint8(v >> 24)
+	}
+	return c
+}
@@ -0,0 +1,194 @@
+package cr1ckt
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+const testTMX = `<?xml version="1.0" encoding="UTF-8"?>
+<map version="1.10" orientation="orthogonal" width="3" height="2" tilewidth="16" tileheight="16" infinite="0" backgroundcolor="#102030">
+ <properties>
+  <property name="Gravity" type="int" value="25"/>
+ </properties>
+ <tileset firstgid="1" tilewidth="16" tileheight="16" tilecount="64" columns="8">
+  <image source="tileset.png" width="128" height="128"/>
+  <tile id="9">
+   <animation>
+    <frame tileid="9" duration="250"/>
+    <frame tileid="10" duration="250"/>
+   </animation>
+  </tile>
+ </tileset>
+ <layer id="1" name="Background" width="3" height="2" opacity="0.5" parallaxx="0.5">
+  <data encoding="csv">0,0,0,0,0,2</data>
+ </layer>
+ <layer id="2" name="Tiles" width="3" height="2">
+  <data encoding="csv">
+1,2147483650,0,
+0,0,3
+</data>
+ </layer>
+ <objectgroup id="3" name="Entities">
+  <object id="4" type="Cricket" x="8" y="16" width="8" height="8"/>
+  <object id="5" class="Door" x="32" y="0" width="16" height="16">
+   <properties>
+    <property name="Switch" type="object" value="6"/>
+   </properties>
+  </object>
+  <object id="6" name="Switch" gid="10" x="0" y="32" width="16" height="16"/>
+ </objectgroup>
+</map>
+`
+
+const testTMJ = `{
+ "orientation": "orthogonal", "width": 3, "height": 2, "tilewidth": 16, "tileheight": 16,
+ "infinite": false, "backgroundcolor": "#102030",
+ "properties": [{"name": "Gravity", "type": "int", "value": 25}],
+ "tilesets": [{"firstgid": 1, "source": "tiles.tsj"}],
+ "layers": [
+  {"type": "tilelayer", "name": "Background", "width": 3, "height": 2, "opacity": 0.5, "parallaxx": 0.5,
+   "visible": true, "data": [0, 0, 0, 0, 0, 2]},
+  {"type": "group", "name": "Front", "visible": true, "layers": [
+   {"type": "tilelayer", "name": "Tiles", "width": 3, "height": 2, "visible": true,
+    "encoding": "base64", "compression": "zlib", "data": "eJxjZGBgYGJgaGBAAsxADAAI5ACH"},
+   {"type": "objectgroup", "name": "Entities", "visible": true, "objects": [
+    {"id": 4, "type": "Cricket", "x": 8, "y": 16, "width": 8, "height": 8},
+    {"id": 5, "class": "Door", "x": 32, "y": 0, "width": 16, "height": 16,
+     "properties": [{"name": "Switch", "type": "object", "value": 6}]},
+    {"id": 6, "name": "Switch", "gid": 10, "x": 0, "y": 32, "width": 16, "height": 16}
+   ]}
+  ]}
+ ]
+}`
+
+const testTSJ = `{
+ "tilewidth": 16, "tileheight": 16, "tilecount": 64, "columns": 8, "image": "tileset.png",
+ "tiles": [{"id": 9, "animation": [{"tileid": 9, "duration": 250}, {"tileid": 10, "duration": 250}]}]
+}`
+
+func TestReadTiled(t *testing.T) {
+	files := map[string]string{"assets/packs/tiles.tsj": testTSJ}
+	readFile := func(name string) ([]byte, error) {
+		return []byte(files[name]), nil
+	}
+
+	for _, name := range []string{"assets/packs/Meadow.tmx", "assets/packs/Meadow.tmj"} {
+		data := testTMX
+		if name == "assets/packs/Meadow.tmj" {
+			data = testTMJ
+		}
+		maps, err := ReadTiled(name, []byte(data), readFile)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(maps.Levels()) != 1 {
+			t.Fatalf("%s has %d levels, want 1", name, len(maps.Levels()))
+		}
+		level := maps.Levels()[0]
+
+		if level.Identifier != "Meadow" || level.Width != 48 || level.Height != 32 || !level.Loaded {
+			t.Errorf("%s level is %s %dx%d (loaded %v), want Meadow 48x32", name,
+				level.Identifier, level.Width, level.Height, level.Loaded)
+		}
+		if want := (color.NRGBA{0x10, 0x20, 0x30, 0xff}); level.BGColor != want {
+			t.Errorf("%s background is %v, want %v", name, level.BGColor, want)
+		}
+		if p := level.PropertyByIdentifier("Gravity"); p == nil || p.AsInt() != 25 {
+			t.Errorf("%s Gravity is %v, want 25", name, p)
+		}
+
+		var layers []string
+		for _, l := range level.Layers {
+			layers = append(layers, l.Identifier)
+		}
+		if want := []string{"Entities", "Tiles", "Background"}; !reflect.DeepEqual(layers, want) {
+			t.Fatalf("%s layers are %v, want %v", name, layers, want)
+		}
+		if bg := level.Layers[2]; bg.Opacity != 0.5 || bg.ParallaxX != 0.5 || bg.ParallaxY != 0 {
+			t.Errorf("%s background has opacity %v and parallax %v, %v, want 0.5 and 0.5, 0",
+				name, bg.Opacity, bg.ParallaxX, bg.ParallaxY)
+		}
+
+		tiles := level.Layers[1]
+		if tiles.Type != MapLayerTiles || len(tiles.Tiles) != 3 {
+			t.Fatalf("%s tile layer is %s with %d tiles, want 3 tiles", name, tiles.Type, len(tiles.Tiles))
+		}
+		for i, want := range []MapTile{
+			{ID: 0, Position: []int{0, 0}, Src: image.Rect(0, 0, 16, 16)},
+			{ID: 1, Position: []int{16, 0}, Src: image.Rect(16, 0, 32, 16), FlipX: true},
+			{ID: 2, Position: []int{32, 16}, Src: image.Rect(32, 0, 48, 16)},
+		} {
+			got := *tiles.Tiles[i]
+			if got.Tileset == nil || got.Tileset.Path != "packs/tileset.png" {
+				t.Errorf("%s tile %d has tileset %v, want packs/tileset.png", name, i, got.Tileset)
+			}
+			got.Tileset = nil
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%s tile %d is %+v, want %+v", name, i, got, want)
+			}
+		}
+		if tile := tiles.TileAt(40, 20); tile != tiles.Tiles[2] {
+			t.Errorf("%s tile at 40, 20 is %v, want %v", name, tile, tiles.Tiles[2])
+		}
+
+		entities := level.Layers[0]
+		if entities.Type != MapLayerEntities || len(entities.Entities) != 3 {
+			t.Fatalf("%s entity layer is %s with %d entities, want 3", name, entities.Type, len(entities.Entities))
+		}
+		if e := entities.EntityByIdentifier("Cricket"); e == nil || !reflect.DeepEqual(e.Position, []int{8, 16}) {
+			t.Errorf("%s cricket is %+v, want it at 8, 16", name, e)
+		}
+		door := entities.EntityByIdentifier("Door")
+		if door == nil {
+			t.Fatalf("%s has no door", name)
+		}
+		if refs := EntityRefs(door, "Switch"); !reflect.DeepEqual(refs, []string{"6"}) {
+			t.Errorf("%s door is wired to %v, want [6]", name, refs)
+		}
+
+		// Tile objects are drawn with their tile and animated tiles have frames
+		sw := entities.EntityByIdentifier("Switch")
+		if sw == nil || sw.Iid != "6" || !reflect.DeepEqual(sw.Position, []int{0, 16}) {
+			t.Fatalf("%s switch is %+v, want it at 0, 16", name, sw)
+		}
+		if sw.Tile == nil || sw.Tile.Src != image.Rect(16, 16, 32, 32) {
+			t.Errorf("%s switch tile is %+v, want 16, 16, 32, 32", name, sw.Tile)
+		}
+		frames := sw.PropertyByIdentifier("Frames")
+		if frames == nil || len(frames.AsTiles()) != 2 {
+			t.Errorf("%s switch has frames %v, want 2", name, frames)
+		}
+		if p := sw.PropertyByIdentifier("FrameTicks"); p == nil || p.AsInt() != 15 {
+			t.Errorf("%s switch FrameTicks is %v, want 15", name, p)
+		}
+	}
+}
+
+func TestReadTiledUnsupported(t *testing.T) {
+	cases := []struct {
+		data    string
+		comment string
+	}{
+		{`<map orientation="isometric" width="1" height="1" tilewidth="16" tileheight="16"/>`, "isometric"},
+		{`<map orientation="orthogonal" width="1" height="1" tilewidth="16" tileheight="16" infinite="1"/>`, "infinite"},
+		{`<map orientation="orthogonal" width="1" height="1" tilewidth="16" tileheight="16">
+ <tileset firstgid="1" tilewidth="16" tileheight="16" columns="0"/>
+</map>`, "image collection"},
+		{`<map orientation="orthogonal" width="1" height="1" tilewidth="16" tileheight="8"/>`, "non-square tiles"},
+		{`<map orientation="orthogonal" width="2" height="1" tilewidth="16" tileheight="16">
+ <tileset firstgid="1" tilewidth="16" tileheight="16" tilecount="64" columns="8">
+  <image source="tileset.png" width="128" height="128"/>
+ </tileset>
+ <layer id="1" name="Tiles" width="2" height="1">
+  <data encoding="csv">1,536870913</data>
+ </layer>
+</map>`, "rotated tile"},
+	}
+	for _, c := range cases {
+		if _, err := ReadTiled("assets/packs/Bad.tmx", []byte(c.data), nil); err == nil {
+			t.Errorf("Reading %s map should fail", c.comment)
+		}
+	}
+}
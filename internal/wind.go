@@ -0,0 +1,191 @@
+// Copyright 2021 Siôn le Roux.  All rights reserved.
+// Use of this source code is subject to an MIT-style
+// licence which can be found in the LICENSE file.
+
+package cr1ckt
+
+import (
+	"image"
+	"image/color"
+	"log"
+	"math/rand"
+
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+func init() {
+	factory := func(g *Game, e *MapEntity) Entity {
+		return NewZone(e)
+	}
+	RegisterEntity("Wind", factory)
+	RegisterEntity("Current", factory)
+}
+
+// ZoneMaxSpeed is the fastest zones can push the cricket along each axis, in
+// pixels per tick, it's half a tile so it can't be blown through a tile
+const ZoneMaxSpeed = 8
+
+// Zone is an area of the level that pushes the cricket while it's inside,
+// made from a "Wind" or "Current" entity in LDtk
+type Zone struct {
+	Rect    image.Rectangle
+	Force   image.Point // Added to the cricket's velocity every movement step
+	Current bool        // Currents sweep the cricket along even when it's standing
+	streaks []image.Point
+}
+
+// NewZone returns a new Zone covering the given LDtk entity, the entity's
+// Direction field should be Left, Right, Up or Down and Strength is how much
+// velocity it adds per movement step
+func NewZone(e *MapEntity) *Zone {
+	z := &Zone{
+		Rect:    entityRect(e),
+		Current: e.Identifier == "Current",
+	}
+
+	strength := 1
+	if p := e.PropertyByIdentifier("Strength"); p != nil && !p.IsNull() {
+		strength = p.AsInt()
+	}
+	direction := "Right"
+	if p := e.PropertyByIdentifier("Direction"); p != nil && !p.IsNull() {
+		direction = p.AsString()
+	}
+	// Remember that the cricket's velocity is positive going left and up
+	switch direction {
+	case "Left":
+		z.Force = image.Pt(strength, 0)
+	case "Right":
+		z.Force = image.Pt(-strength, 0)
+	case "Up":
+		z.Force = image.Pt(0, strength)
+	case "Down":
+		z.Force = image.Pt(0, -strength)
+	default:
+		log.Printf("%s has unknown direction %q\n", e.Identifier, direction)
+	}
+
+	// One streak for every 32×32 pixels of area
+	for i := 0; i < z.Rect.Dx()*z.Rect.Dy()/(32*32)+1; i++ {
+		z.streaks = append(z.streaks, image.Pt(
+			z.Rect.Min.X+rand.Intn(z.Rect.Dx()),
+			z.Rect.Min.Y+rand.Intn(z.Rect.Dy()),
+		))
+	}
+
+	return z
+}
+
+// pushZones pushes the cricket with every zone it's inside, in the air the
+// zones' forces are added to its velocity, which they can't push past
+// ZoneMaxSpeed, and on the ground currents sweep it along sideways without
+// stopping it from priming a jump. It returns how far the cricket should be
+// swept along the ground this step.
+func (g *Game) pushZones() int {
+	c := g.Cricket
+	sweep := 0
+	for _, z := range g.zones {
+		if !z.Rect.Overlaps(c.Hitbox()) {
+			continue
+		}
+		if c.Jumping {
+			c.Velocity.X = push(c.Velocity.X, z.Force.X)
+			c.Velocity.Y = push(c.Velocity.Y, z.Force.Y)
+		} else if z.Current {
+			sweep += z.Force.X
+		}
+	}
+	return clamp(sweep, -ZoneMaxSpeed, ZoneMaxSpeed)
+}
+
+// push adds a zone's force to one axis of the cricket's velocity, the force
+// can't push it past ZoneMaxSpeed but it doesn't slow down a jump that's
+// already going faster that way either
+func push(v, force int) int {
+	if force > 0 && v+force > ZoneMaxSpeed {
+		if v > ZoneMaxSpeed {
+			return v
+		}
+		return ZoneMaxSpeed
+	}
+	if force < 0 && v+force < -ZoneMaxSpeed {
+		if v < -ZoneMaxSpeed {
+			return v
+		}
+		return -ZoneMaxSpeed
+	}
+	return v + force
+}
+
+// sweep moves the standing cricket sideways along the ground, it doesn't go
+// out of the level and it falls if it's swept off a ledge
+func (g *Game) sweep(sweep int) {
+	c := g.Cricket
+	level := g.Levels[g.Level]
+	// Velocity is positive going left
+	c.Position.X -= sweep
+	if hitbox := c.Hitbox(); hitbox.Min.X < 0 || hitbox.Max.X > level.Width {
+		c.Position.X += sweep
+		return
+	}
+	if !g.onGround() {
+		c.Jumping = true
+		c.State = Landing
+		c.Peak = c.Position.Y
+	}
+}
+
+// Hitbox returns the area of the zone
+func (z *Zone) Hitbox() image.Rectangle {
+	return z.Rect
+}
+
+// Update drifts the streaks that show which way the zone pushes, wrapping
+// them around when they leave the zone
+func (z *Zone) Update(g *Game) {
+	for i, s := range z.streaks {
+		// Velocity is backwards so the streaks go the opposite way
+		s = s.Sub(z.Force)
+		if !s.In(z.Rect) {
+			s.X = z.Rect.Min.X + mod(s.X-z.Rect.Min.X, z.Rect.Dx())
+			s.Y = z.Rect.Min.Y + mod(s.Y-z.Rect.Min.Y, z.Rect.Dy())
+		}
+		z.streaks[i] = s
+	}
+}
+
+// Draw draws the zone's streaks to the camera surface
+func (z *Zone) Draw(g *Game) {
+	clr := color.RGBA{0xff, 0xff, 0xff, 0x40}
+	if z.Current {
+		clr = color.RGBA{0x9c, 0xd6, 0xf0, 0x60}
+	}
+	// Streaks are longer along the direction they move
+	w, h := 1.0, 1.0
+	if z.Force.X != 0 {
+		w = 8
+	}
+	if z.Force.Y != 0 {
+		h = 8
+	}
+	for _, s := range z.streaks {
+		x, y := g.cam.GetTranslation(float64(s.X), float64(s.Y)).GeoM.Apply(0, 0)
+		ebitenutil.DrawRect(g.cam.Surface, x, y, w, h, clr)
+	}
+}
+
+// mod is the modulo operation that is always positive, unlike Go's % operator
+func mod(a, b int) int {
+	return (a%b + b) % b
+}
+
+// clamp limits v to between lo and hi
+func clamp(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
@@ -0,0 +1,50 @@
+package cr1ckt
+
+import (
+	"image"
+	"testing"
+)
+
+func TestPushZones(t *testing.T) {
+	cases := []struct {
+		zone      Zone
+		jumping   bool
+		velocity  image.Point
+		want      image.Point
+		wantSweep int
+		comment   string
+	}{
+		{Zone{Force: image.Pt(-1, 0)}, true, image.Pt(0, 0), image.Pt(-1, 0), 0, "gentle wind"},
+		{Zone{Force: image.Pt(3, 0)}, true, image.Pt(7, 2), image.Pt(ZoneMaxSpeed, 2), 0, "strong wind capped"},
+		{Zone{Force: image.Pt(0, 4)}, true, image.Pt(0, 6), image.Pt(0, ZoneMaxSpeed), 0, "strong updraft capped"},
+		{Zone{Force: image.Pt(1, 0)}, true, image.Pt(10, 3), image.Pt(10, 3), 0, "tailwind on a full jump"},
+		{Zone{Force: image.Pt(-1, 0)}, true, image.Pt(10, 3), image.Pt(9, 3), 0, "headwind on a full jump"},
+		{Zone{Force: image.Pt(0, -2)}, true, image.Pt(-10, -9), image.Pt(-10, -9), 0, "downdraft on a fast fall"},
+		{Zone{Force: image.Pt(-2, 0)}, false, image.Pt(0, -5), image.Pt(0, -5), 0, "wind on the ground"},
+		{Zone{Force: image.Pt(-2, 0), Current: true}, false, image.Pt(0, -5), image.Pt(0, -5), -2, "current on the ground"},
+		{Zone{Force: image.Pt(0, 3), Current: true}, false, image.Pt(0, -5), image.Pt(0, -5), 0, "upwards current on the ground"},
+	}
+	for _, c := range cases {
+		c.zone.Rect = image.Rect(0, 0, 100, 100)
+		g := &Game{
+			Cricket: &Cricket{hitbox: image.Rect(0, 0, 10, 10), Jumping: c.jumping, Velocity: c.velocity},
+			zones:   []*Zone{&c.zone},
+		}
+		sweep := g.pushZones()
+		if g.Cricket.Velocity != c.want || sweep != c.wantSweep {
+			t.Errorf("Cricket in %s has velocity %v and is swept %d, want %v and %d",
+				c.comment, g.Cricket.Velocity, sweep, c.want, c.wantSweep)
+		}
+		if g.Cricket.Jumping != c.jumping {
+			t.Errorf("Cricket in %s changed from jumping %v", c.comment, c.jumping)
+		}
+	}
+
+	outside := &Game{
+		Cricket: &Cricket{hitbox: image.Rect(200, 200, 210, 210), Jumping: true, Velocity: image.Pt(10, 0)},
+		zones:   []*Zone{{Rect: image.Rect(0, 0, 100, 100), Force: image.Pt(1, 0)}},
+	}
+	if outside.pushZones(); outside.Cricket.Velocity != image.Pt(10, 0) {
+		t.Errorf("Cricket outside the zone has velocity %v, want it unchanged", outside.Cricket.Velocity)
+	}
+}
@@ -11,7 +11,13 @@ import (
 )
 
 // WinScreen is the screen that's displayed when you win the game
-type WinScreen int
+type WinScreen struct {
+	Jumps      int // How many jumps it took
+	Seeds      int // How many seeds were collected
+	TotalSeeds int // How many seeds there were in the level
+	BestSeeds  int // The most seeds ever collected in the level
+	Deaths     int // How many times the cricket died on the way
+}
 
 // Draw draws the win screen to a provided image
 func (w WinScreen) Draw(g *Game, screen *ebiten.Image) {
@@ -21,11 +27,18 @@ func (w WinScreen) Draw(g *Game, screen *ebiten.Image) {
 	txtH := (txtF.Max.Y - txtF.Min.Y).Ceil() * 2
 	text.Draw(screen, txt, g.fontBig, g.Width/2-txtW, txtH, color.White)
 
-	txt = fmt.Sprintf("%d JUMPS", w)
+	txt = fmt.Sprintf("%d JUMPS %d DEATHS", w.Jumps, w.Deaths)
 	txtF, _ = font.BoundString(g.fontBig, txt)
 	txtW = (txtF.Max.X - txtF.Min.X).Ceil() / 2
 	text.Draw(screen, txt, g.fontBig, g.Width/2-txtW, txtH*2, color.White)
 
+	if w.TotalSeeds > 0 {
+		txt = fmt.Sprintf("%d/%d SEEDS (BEST %d)", w.Seeds, w.TotalSeeds, w.BestSeeds)
+		txtF, _ = font.BoundString(g.fontSmall, txt)
+		txtW = (txtF.Max.X - txtF.Min.X).Ceil() / 2
+		text.Draw(screen, txt, g.fontSmall, g.Width/2-txtW, txtH*2+txtH/2, color.White)
+	}
+
 	op := &ebiten.DrawImageOptions{}
 	op.GeoM.Translate(float64(g.Width/2-g.Cricket.Width/2), float64(txtH*3))
 	screen.DrawImage(g.Cricket.Image.SubImage(image.Rect(
@@ -0,0 +1,123 @@
+// Copyright 2021 Siôn le Roux.  All rights reserved.
+// Use of this source code is subject to an MIT-style
+// licence which can be found in the LICENSE file.
+
+package cr1ckt
+
+import (
+	"image"
+	"log"
+)
+
+// WorldRect returns where a level is in its world
+func WorldRect(level *MapLevel) image.Rectangle {
+	return image.Rect(0, 0, level.Width, level.Height).
+		Add(image.Pt(level.WorldX, level.WorldY))
+}
+
+// neighbours returns the levels touching the current one in its world, if
+// its world lays levels out next to each other
+func (g *Game) neighbours() []int {
+	current := g.Levels[g.Level]
+	if !current.Connected {
+		return nil
+	}
+	// Levels sharing an edge touch but don't overlap, so grow the level one
+	// way at a time to find them; levels only touching at a corner stay out
+	rect := WorldRect(current)
+	across := image.Rect(rect.Min.X-1, rect.Min.Y, rect.Max.X+1, rect.Max.Y)
+	down := image.Rect(rect.Min.X, rect.Min.Y-1, rect.Max.X, rect.Max.Y+1)
+	var found []int
+	for i, level := range g.Levels {
+		if i == g.Level || level.World != current.World {
+			continue
+		}
+		if r := WorldRect(level); r.Overlaps(across) || r.Overlaps(down) {
+			found = append(found, i)
+		}
+	}
+	return found
+}
+
+// levelOffset returns where a level is compared to the current one, in the
+// current level's pixels
+func (g *Game) levelOffset(i int) image.Point {
+	current, level := g.Levels[g.Level], g.Levels[i]
+	return image.Pt(level.WorldX-current.WorldX, level.WorldY-current.WorldY)
+}
+
+// neighbourAt returns the neighbouring level at a point outside the current
+// level, or -1 if there isn't one there
+func (g *Game) neighbourAt(p image.Point) int {
+	for _, i := range g.neighbours() {
+		level := g.Levels[i]
+		rect := image.Rect(0, 0, level.Width, level.Height).Add(g.levelOffset(i))
+		if p.In(rect) {
+			return i
+		}
+	}
+	return -1
+}
+
+// worldBounds returns the area the camera can see around the current level,
+// which takes in its neighbours so the camera scrolls across to them
+func (g *Game) worldBounds() image.Rectangle {
+	current := g.Levels[g.Level]
+	bounds := image.Rect(0, 0, current.Width, current.Height)
+	for _, i := range g.neighbours() {
+		level := g.Levels[i]
+		bounds = bounds.Union(image.Rect(0, 0, level.Width, level.Height).Add(g.levelOffset(i)))
+	}
+	return bounds
+}
+
+// crossing checks whether the middle of the cricket has left the current
+// level into a neighbouring one and moves it there if so, returning how far
+// everything moved so positions kept from before can be moved too
+func (g *Game) crossing() image.Point {
+	hitbox := g.Cricket.Hitbox()
+	center := hitbox.Min.Add(hitbox.Size().Div(2))
+	level := g.Levels[g.Level]
+	if center.In(image.Rect(0, 0, level.Width, level.Height)) {
+		return image.Point{}
+	}
+	if i := g.neighbourAt(center); i >= 0 {
+		return g.Cross(i)
+	}
+	return image.Point{}
+}
+
+// Cross moves the cricket into a neighbouring level without stopping, it
+// keeps going the way it was and the camera scrolls on across the seam. The
+// run carries on too, so the blackness, jumps and time are kept. It returns
+// how far the cricket moved to be in the new level's pixels.
+func (g *Game) Cross(level int) image.Point {
+	offset := image.Point{}.Sub(g.levelOffset(level))
+	log.Println("Crossing into", g.Levels[level].Identifier)
+	g.saveSeeds()
+
+	// Blackness in world space is on the old level's grid
+	if g.rules.BlacknessSpace == BlacknessWorld {
+		g.blackness = shiftSquares(g.blackness, offset)
+		g.revealed = shiftSquares(g.revealed, offset)
+		if g.chirp != nil {
+			g.chirp.Center = g.chirp.Center.Add(offset)
+		}
+	}
+
+	cricket := g.Cricket
+	cricket.Position = cricket.Position.Add(offset)
+	cricket.Peak += offset.Y
+	g.entry = []int{cricket.Position.X, cricket.Position.Y}
+	g.switchLevel(level)
+	g.Cricket = cricket
+	// The background and particles carry on across the seam
+	g.particles.Shift(offset)
+	if g.parallaxName() != g.parallaxSet {
+		g.startParallax()
+	}
+	// Dying from here on puts the cricket back where it came in
+	g.respawn = g.snapshot(cricket.Position)
+	g.view.Shift(offset)
+	return offset
+}
@@ -0,0 +1,49 @@
+package cr1ckt
+
+import (
+	"image"
+	"reflect"
+	"testing"
+)
+
+func TestNeighbours(t *testing.T) {
+	level := func(x, y int) *MapLevel {
+		return &MapLevel{World: "Meadow", Connected: true, WorldX: x, WorldY: y, Width: 100, Height: 100}
+	}
+	levels := []*MapLevel{
+		level(0, 0),     // current
+		level(100, 0),   // right
+		level(0, 100),   // below
+		level(300, 0),   // too far away
+		level(-100, 0),  // left, but in another world
+		level(100, 100), // only the corners touch
+	}
+	levels[4].World = "Pond"
+	g := &Game{Levels: levels}
+
+	if got, want := g.neighbours(), []int{1, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Neighbours are %v, want %v", got, want)
+	}
+	cases := []struct {
+		p    image.Point
+		want int
+	}{
+		{image.Pt(150, 50), 1},
+		{image.Pt(50, 150), 2},
+		{image.Pt(-50, 50), -1},
+		{image.Pt(150, 150), -1},
+	}
+	for _, c := range cases {
+		if got := g.neighbourAt(c.p); got != c.want {
+			t.Errorf("Neighbour at %v is %d, want %d", c.p, got, c.want)
+		}
+	}
+	if got, want := g.worldBounds(), image.Rect(0, 0, 200, 200); got != want {
+		t.Errorf("World bounds are %v, want %v", got, want)
+	}
+
+	levels[0].Connected = false
+	if got := g.neighbours(); got != nil {
+		t.Errorf("Levels in a linear world have neighbours %v", got)
+	}
+}